@@ -1,9 +1,13 @@
 // Copyright 2023 Cryptlex, LLC. All rights reserved.
 
+//go:build cgo && !lexactivator_stub
+// +build cgo,!lexactivator_stub
+
 package lexactivator
 
 /*
 #include <stdio.h>
+#include <stdint.h>
 
 // The gateway functions
 void licenseCallbackCgoGateway(int status)
@@ -18,10 +22,10 @@ void releaseUpdateCallbackCgoGateway(int status)
 	releaseUpdateCallbackWrapper(status);
 }
 
-void newReleaseUpdateCallbackCgoGateway(int status, char *releaseJson, void* unused)
+void newReleaseUpdateCallbackCgoGateway(int status, char *releaseJson, void* userData)
 {
-	void newReleaseUpdateCallbackWrapper(int, char *);
-	newReleaseUpdateCallbackWrapper(status, releaseJson);
+	void newReleaseUpdateCallbackWrapper(int, char *, uintptr_t);
+	newReleaseUpdateCallbackWrapper(status, releaseJson, (uintptr_t)userData);
 }
 */
 import "C"
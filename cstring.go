@@ -1,4 +1,6 @@
+//go:build (linux || darwin) && cgo && !lexactivator_stub
 // +build linux darwin
+// +build cgo,!lexactivator_stub
 
 package lexactivator
 
@@ -1,9 +1,19 @@
 package lexactivator
 
+import (
+	headers "github.com/Exostellar/lexactivator-go/lexactivator"
+	libs "github.com/Exostellar/lexactivator-go/libs"
+	darwinamd64 "github.com/Exostellar/lexactivator-go/libs/darwin_amd64"
+	linuxamd64 "github.com/Exostellar/lexactivator-go/libs/linux_amd64"
+	linuxarm64 "github.com/Exostellar/lexactivator-go/libs/linux_arm64"
+	windowsamd64 "github.com/Exostellar/lexactivator-go/libs/windows_amd64"
+)
+
 func Dummy() {
-	DummyHeaders()
-	DarwinAmd64()
-	LinuxAmd64()
-	LinuxArm64()
-	WindowsAmd64()
+	headers.DummyHeaders()
+	libs.DummyLibraries()
+	darwinamd64.DarwinAmd64()
+	linuxamd64.LinuxAmd64()
+	linuxarm64.LinuxArm64()
+	windowsamd64.WindowsAmd64()
 }
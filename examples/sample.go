@@ -4,7 +4,7 @@ import (
 	"bufio"
 	"fmt"
 	"os"
-	"github.com/cryptlex/lexactivator-go"
+	"github.com/Exostellar/lexactivator-go"
 )
 
 // server sync license callback
@@ -16,7 +16,7 @@ func licenseCallback(status int) {
 	} else if status == lexactivator.LA_SUSPENDED {
 		fmt.Println("License is genuinely activated, but has been suspended")
 	} else {
-		fmt.Println("License error status:", status)
+		fmt.Println("License error status:", lexactivator.Status(status))
 	}
 }
 
@@ -24,19 +24,19 @@ func initData() {
 	var status int
 	status = lexactivator.SetProductData("PASTE_CONTENT_OF_PRODUCT.DAT_FILE")
 	if lexactivator.LA_OK != status {
-		fmt.Println("Error Code:", status)
+		fmt.Println("Error Code:", lexactivator.Status(status))
 		os.Exit(1)
 	}
 
 	status = lexactivator.SetProductId("PASTE_PRODUCT_ID", lexactivator.LA_USER)
 	if lexactivator.LA_OK != status {
-		fmt.Println("Error Code:", status)
+		fmt.Println("Error Code:", lexactivator.Status(status))
 		os.Exit(1)
 	}
 	// Set this to the release version of your app
 	status = lexactivator.SetReleaseVersion("1.0.0")
 	if lexactivator.LA_OK != status {
-		fmt.Println("Error Code:", status)
+		fmt.Println("Error Code:", lexactivator.Status(status))
 		os.Exit(1)
 	}
 }
@@ -45,13 +45,13 @@ func activate() {
 	var status int
 	status = lexactivator.SetLicenseKey("PASTE_LICENSE_KEY")
 	if lexactivator.LA_OK != status {
-		fmt.Println("Error Code:", status)
+		fmt.Println("Error Code:", lexactivator.Status(status))
 		os.Exit(1)
 	}
 
 	status = lexactivator.SetActivationMetadata("key1", "value1")
 	if lexactivator.LA_OK != status {
-		fmt.Println("Error Code:", status)
+		fmt.Println("Error Code:", lexactivator.Status(status))
 		os.Exit(1)
 	}
 
@@ -59,7 +59,7 @@ func activate() {
 	if lexactivator.LA_OK == status || lexactivator.LA_EXPIRED == status || lexactivator.LA_SUSPENDED == status {
 		fmt.Println("License activated successfully:", status)
 	} else {
-		fmt.Println("License activation failed:", status)
+		fmt.Println("License activation failed:", lexactivator.Status(status))
 	}
 }
 
@@ -67,7 +67,7 @@ func activateTrial() {
 	var status int
 	status = lexactivator.SetTrialActivationMetadata("key1", "value1")
 	if lexactivator.LA_OK != status {
-		fmt.Println("Error Code:", status)
+		fmt.Println("Error Code:", lexactivator.Status(status))
 		os.Exit(1)
 	}
 
@@ -77,7 +77,7 @@ func activateTrial() {
 	} else if lexactivator.LA_TRIAL_EXPIRED == status {
 		fmt.Println("Product trial has expired!")
 	} else {
-		fmt.Println("Product trial activation failed:", status)
+		fmt.Println("Product trial activation failed:", lexactivator.Status(status))
 	}
 }
 
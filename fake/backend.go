@@ -0,0 +1,1123 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+// Package fake provides an in-memory lexactivator.Backend whose license
+// state can be scripted from tests, so that code depending on the license
+// state can be tested without the native library or a Cryptlex account.
+//
+//	backend := fake.New()
+//	backend.Update(func(s *fake.State) {
+//		s.License.Key = "LICENSE-KEY"
+//		s.License.Status = lexactivator.LA_EXPIRED
+//	})
+//	client, err := lexactivator.New(lexactivator.Config{
+//		ProductData: "data",
+//		ProductId:   "id",
+//		Backend:     backend,
+//	})
+package fake
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+)
+
+// Backend is an in-memory implementation of lexactivator.Backend. It is
+// safe for concurrent use.
+type Backend struct {
+	mu       sync.Mutex
+	state    State
+	failures map[string]int
+	calls    map[string]int
+
+	licenseCallback func(int)
+	pending         sync.WaitGroup
+
+	clock Clock
+}
+
+var _ lexactivator.Backend = (*Backend)(nil)
+
+// New returns a fake backend with a node-locked license that activates
+// with any license key and an allowed trial.
+func New() *Backend {
+	return &Backend{
+		state:    newState(),
+		failures: map[string]int{},
+		calls:    map[string]int{},
+		clock:    systemClock{},
+	}
+}
+
+// SetClock sets the clock used for trial and local trial expiry.
+func (b *Backend) SetClock(clock Clock) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.clock = clock
+}
+
+// Update runs script with exclusive access to the state of the backend.
+func (b *Backend) Update(script func(s *State)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	script(&b.state)
+	b.state.normalize()
+}
+
+// State returns a copy of the current state of the backend.
+func (b *Backend) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state.clone()
+}
+
+// Fail makes every following call of the named Backend method, e.g.
+// "ActivateLicense", return status until Recover is called.
+func (b *Backend) Fail(method string, status int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures[method] = status
+}
+
+// Recover removes the failure injected by Fail for the named methods, or
+// all injected failures if no method is given.
+func (b *Backend) Recover(methods ...string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(methods) == 0 {
+		b.failures = map[string]int{}
+	}
+	for _, method := range methods {
+		delete(b.failures, method)
+	}
+}
+
+// Calls returns how many times the named Backend method has been called.
+func (b *Backend) Calls(method string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.calls[method]
+}
+
+// Sync simulates a server sync: the callback set by SetLicenseCallback() is
+// invoked with the current license status on a separate goroutine, like
+// the native library does on its sync thread.
+func (b *Backend) Sync() {
+	b.mu.Lock()
+	status := lexactivator.LA_E_ACTIVATION_NOT_FOUND
+	if b.state.Activated {
+		status = b.state.License.Status
+	}
+	b.mu.Unlock()
+	b.SyncWithStatus(status)
+}
+
+// SyncWithStatus invokes the license callback with the given status.
+func (b *Backend) SyncWithStatus(status int) {
+	b.mu.Lock()
+	callback := b.licenseCallback
+	b.mu.Unlock()
+	if callback == nil {
+		return
+	}
+	b.pending.Add(1)
+	go func() {
+		defer b.pending.Done()
+		callback(status)
+	}()
+}
+
+// Wait blocks until every callback started by the backend has returned.
+func (b *Backend) Wait() {
+	b.pending.Wait()
+}
+
+// call records the call of method and returns the status injected by Fail.
+// It must be called with b.mu held.
+func (b *Backend) call(method string) (int, bool) {
+	b.calls[method]++
+	status, ok := b.failures[method]
+	return status, ok
+}
+
+// productCall records the call of method and returns the injected status or
+// LA_E_PRODUCT_ID if the product id has not been set yet. It must be called
+// with b.mu held.
+func (b *Backend) productCall(method string) (int, bool) {
+	if status, ok := b.call(method); ok {
+		return status, true
+	}
+	if b.state.ProductId == "" {
+		return lexactivator.LA_E_PRODUCT_ID, true
+	}
+	return lexactivator.LA_OK, false
+}
+
+// licenseCall is productCall for functions that need an activated license.
+func (b *Backend) licenseCall(method string) (int, bool) {
+	if status, ok := b.productCall(method); ok {
+		return status, true
+	}
+	if !b.state.Activated {
+		return lexactivator.LA_FAIL, true
+	}
+	return lexactivator.LA_OK, false
+}
+
+func unixTimestamp(t time.Time) uint {
+	if t.IsZero() {
+		return 0
+	}
+	return uint(t.Unix())
+}
+
+const maxMetadataLength = 256
+
+func validVersion(version string) bool {
+	_, err := lexactivator.ParseVersion(version)
+	return err == nil
+}
+
+func (b *Backend) SetProductFile(filePath string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.call("SetProductFile"); ok {
+		return status
+	}
+	if _, err := os.Stat(filePath); err != nil {
+		return lexactivator.LA_E_FILE_PATH
+	}
+	b.state.ProductFile = filePath
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) SetProductData(productData string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.call("SetProductData"); ok {
+		return status
+	}
+	if productData == "" {
+		return lexactivator.LA_E_PRODUCT_DATA
+	}
+	b.state.ProductData = productData
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) SetProductId(productId string, flags uint) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.call("SetProductId"); ok {
+		return status
+	}
+	if b.state.ProductFile == "" && b.state.ProductData == "" {
+		return lexactivator.LA_E_PRODUCT_DATA
+	}
+	if productId == "" {
+		return lexactivator.LA_E_PRODUCT_ID
+	}
+	switch flags {
+	case lexactivator.LA_USER, lexactivator.LA_SYSTEM, lexactivator.LA_IN_MEMORY:
+	default:
+		return lexactivator.LA_FAIL
+	}
+	b.state.ProductId = productId
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) SetDataDirectory(directoryPath string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.call("SetDataDirectory"); ok {
+		return status
+	}
+	if info, err := os.Stat(directoryPath); err != nil || !info.IsDir() {
+		return lexactivator.LA_E_FILE_PERMISSION
+	}
+	b.state.DataDirectory = directoryPath
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) SetCustomDeviceFingerprint(fingerprint string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("SetCustomDeviceFingerprint"); ok {
+		return status
+	}
+	if len(fingerprint) < 64 || len(fingerprint) > 256 {
+		return lexactivator.LA_E_CUSTOM_FINGERPRINT_LENGTH
+	}
+	b.state.CustomFingerprint = fingerprint
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) SetLicenseKey(licenseKey string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("SetLicenseKey"); ok {
+		return status
+	}
+	if licenseKey == "" {
+		return lexactivator.LA_E_LICENSE_KEY
+	}
+	b.state.LicenseKey = licenseKey
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) SetLicenseUserCredential(email string, password string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("SetLicenseUserCredential"); ok {
+		return status
+	}
+	b.state.UserEmail = email
+	b.state.UserPassword = password
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) SetLicenseCallback(callbackFunction func(int)) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("SetLicenseCallback"); ok {
+		return status
+	}
+	b.licenseCallback = callbackFunction
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) SetActivationLeaseDuration(leaseDuration uint) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("SetActivationLeaseDuration"); ok {
+		return status
+	}
+	b.state.LeaseDuration = leaseDuration
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) SetActivationMetadata(key string, value string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("SetActivationMetadata"); ok {
+		return status
+	}
+	if status := checkMetadata(key, value); status != lexactivator.LA_OK {
+		return status
+	}
+	b.state.ActivationMetadata[key] = value
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) SetTrialActivationMetadata(key string, value string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("SetTrialActivationMetadata"); ok {
+		return status
+	}
+	if status := checkMetadata(key, value); status != lexactivator.LA_OK {
+		return status
+	}
+	b.state.Trial.Metadata[key] = value
+	return lexactivator.LA_OK
+}
+
+func checkMetadata(key string, value string) int {
+	if len(key) > maxMetadataLength {
+		return lexactivator.LA_E_METADATA_KEY_LENGTH
+	}
+	if len(value) > maxMetadataLength {
+		return lexactivator.LA_E_METADATA_VALUE_LENGTH
+	}
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) SetAppVersion(appVersion string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("SetAppVersion"); ok {
+		return status
+	}
+	if len(appVersion) > maxMetadataLength {
+		return lexactivator.LA_E_APP_VERSION_LENGTH
+	}
+	b.state.AppVersion = appVersion
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) SetReleaseVersion(releaseVersion string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("SetReleaseVersion"); ok {
+		return status
+	}
+	if !validVersion(releaseVersion) {
+		return lexactivator.LA_E_RELEASE_VERSION_FORMAT
+	}
+	b.state.ReleaseVersion = releaseVersion
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) SetReleasePublishedDate(releasePublishedDate uint) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("SetReleasePublishedDate"); ok {
+		return status
+	}
+	b.state.ReleasePublishedDate = releasePublishedDate
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) SetReleasePlatform(releasePlatform string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("SetReleasePlatform"); ok {
+		return status
+	}
+	if len(releasePlatform) > maxMetadataLength {
+		return lexactivator.LA_E_RELEASE_PLATFORM_LENGTH
+	}
+	b.state.ReleasePlatform = releasePlatform
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) SetReleaseChannel(releaseChannel string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("SetReleaseChannel"); ok {
+		return status
+	}
+	if len(releaseChannel) > maxMetadataLength {
+		return lexactivator.LA_E_RELEASE_CHANNEL_LENGTH
+	}
+	b.state.ReleaseChannel = releaseChannel
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) SetOfflineActivationRequestMeterAttributeUses(name string, uses uint) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("SetOfflineActivationRequestMeterAttributeUses"); ok {
+		return status
+	}
+	b.state.OfflineRequestMeterAttributeUses[name] = uses
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) SetNetworkProxy(proxy string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("SetNetworkProxy"); ok {
+		return status
+	}
+	b.state.NetworkProxy = proxy
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) SetCryptlexHost(host string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("SetCryptlexHost"); ok {
+		return status
+	}
+	b.state.CryptlexHost = host
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetProductMetadata(key string, value *string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("GetProductMetadata"); ok {
+		return status
+	}
+	return lookup(b.state.ProductMetadata, key, value)
+}
+
+func (b *Backend) GetProductVersionName(name *string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetProductVersionName"); ok {
+		return status
+	}
+	if b.state.ProductVersionName == "" {
+		return lexactivator.LA_E_PRODUCT_VERSION_NOT_LINKED
+	}
+	*name = b.state.ProductVersionName
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetProductVersionDisplayName(displayName *string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetProductVersionDisplayName"); ok {
+		return status
+	}
+	if b.state.ProductVersionName == "" {
+		return lexactivator.LA_E_PRODUCT_VERSION_NOT_LINKED
+	}
+	*displayName = b.state.ProductVersionDisplayName
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetProductVersionFeatureFlag(name string, enabled *bool, data *string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetProductVersionFeatureFlag"); ok {
+		return status
+	}
+	if b.state.ProductVersionName == "" {
+		return lexactivator.LA_E_PRODUCT_VERSION_NOT_LINKED
+	}
+	flag, ok := b.state.FeatureFlags[name]
+	if !ok {
+		return lexactivator.LA_E_FEATURE_FLAG_NOT_FOUND
+	}
+	*enabled = flag.Enabled
+	*data = flag.Data
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetLicenseMetadata(key string, value *string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetLicenseMetadata"); ok {
+		return status
+	}
+	return lookup(b.state.License.Metadata, key, value)
+}
+
+func (b *Backend) GetLicenseMeterAttribute(name string, allowedUses *uint, totalUses *uint, grossUses *uint) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetLicenseMeterAttribute"); ok {
+		return status
+	}
+	attribute, ok := b.state.License.MeterAttributes[name]
+	if !ok {
+		return lexactivator.LA_E_METER_ATTRIBUTE_NOT_FOUND
+	}
+	*allowedUses = attribute.AllowedUses
+	*totalUses = attribute.TotalUses
+	*grossUses = attribute.GrossUses
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetLicenseKey(licenseKey *string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetLicenseKey"); ok {
+		return status
+	}
+	*licenseKey = b.state.License.Key
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetLicenseAllowedActivations(allowedActivations *uint) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetLicenseAllowedActivations"); ok {
+		return status
+	}
+	*allowedActivations = b.state.License.AllowedActivations
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetLicenseTotalActivations(totalActivations *uint) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetLicenseTotalActivations"); ok {
+		return status
+	}
+	*totalActivations = b.state.License.TotalActivations
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetLicenseExpiryDate(expiryDate *uint) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetLicenseExpiryDate"); ok {
+		return status
+	}
+	*expiryDate = unixTimestamp(b.state.License.ExpiryDate)
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetLicenseMaintenanceExpiryDate(maintenanceExpiryDate *uint) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetLicenseMaintenanceExpiryDate"); ok {
+		return status
+	}
+	*maintenanceExpiryDate = unixTimestamp(b.state.License.MaintenanceExpiryDate)
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetLicenseMaxAllowedReleaseVersion(maxAllowedReleaseVersion *string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetLicenseMaxAllowedReleaseVersion"); ok {
+		return status
+	}
+	*maxAllowedReleaseVersion = b.state.License.MaxAllowedReleaseVersion
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetLicenseUserEmail(email *string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetLicenseUserEmail"); ok {
+		return status
+	}
+	*email = b.state.License.UserEmail
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetLicenseUserName(name *string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetLicenseUserName"); ok {
+		return status
+	}
+	*name = b.state.License.UserName
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetLicenseUserCompany(company *string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetLicenseUserCompany"); ok {
+		return status
+	}
+	*company = b.state.License.UserCompany
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetLicenseUserMetadata(key string, value *string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetLicenseUserMetadata"); ok {
+		return status
+	}
+	return lookup(b.state.License.UserMetadata, key, value)
+}
+
+func (b *Backend) GetLicenseOrganizationName(organizationName *string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetLicenseOrganizationName"); ok {
+		return status
+	}
+	*organizationName = b.state.License.OrganizationName
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetLicenseOrganizationAddress(organizationAddress *lexactivator.OrganizationAddress) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetLicenseOrganizationAddress"); ok {
+		return status
+	}
+	*organizationAddress = b.state.License.OrganizationAddress
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetLicenseType(licenseType *string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetLicenseType"); ok {
+		return status
+	}
+	*licenseType = b.state.License.Type
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetActivationMetadata(key string, value *string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetActivationMetadata"); ok {
+		return status
+	}
+	return lookup(b.state.ActivationMetadata, key, value)
+}
+
+func (b *Backend) GetActivationMode(initialMode *string, currentMode *string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetActivationMode"); ok {
+		return status
+	}
+	*initialMode = string(b.state.License.InitialMode)
+	*currentMode = string(b.state.License.CurrentMode)
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetActivationMeterAttributeUses(name string, uses *uint) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetActivationMeterAttributeUses"); ok {
+		return status
+	}
+	if _, ok := b.state.License.MeterAttributes[name]; !ok {
+		return lexactivator.LA_E_METER_ATTRIBUTE_NOT_FOUND
+	}
+	*uses = b.state.ActivationMeterAttributeUses[name]
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetServerSyncGracePeriodExpiryDate(expiryDate *uint) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GetServerSyncGracePeriodExpiryDate"); ok {
+		return status
+	}
+	*expiryDate = unixTimestamp(b.state.License.GracePeriodExpiryDate)
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetTrialActivationMetadata(key string, value *string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.trialCall("GetTrialActivationMetadata"); ok {
+		return status
+	}
+	return lookup(b.state.Trial.Metadata, key, value)
+}
+
+func (b *Backend) GetTrialExpiryDate(trialExpiryDate *uint) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.trialCall("GetTrialExpiryDate"); ok {
+		return status
+	}
+	*trialExpiryDate = unixTimestamp(b.state.Trial.ExpiryDate)
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetTrialId(trialId *string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.trialCall("GetTrialId"); ok {
+		return status
+	}
+	*trialId = b.state.Trial.Id
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetLocalTrialExpiryDate(trialExpiryDate *uint) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("GetLocalTrialExpiryDate"); ok {
+		return status
+	}
+	if !b.state.LocalTrial.Activated {
+		return lexactivator.LA_FAIL
+	}
+	*trialExpiryDate = unixTimestamp(b.state.LocalTrial.ExpiryDate)
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GetLibraryVersion(libraryVersion *string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.call("GetLibraryVersion"); ok {
+		return status
+	}
+	*libraryVersion = b.state.LibraryVersion
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) CheckForReleaseUpdate(platform string, version string, channel string, callbackFunction func(int)) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("CheckForReleaseUpdate"); ok {
+		return status
+	}
+	if !validVersion(version) {
+		return lexactivator.LA_E_RELEASE_VERSION_FORMAT
+	}
+	if callbackFunction == nil {
+		return lexactivator.LA_OK
+	}
+	status, _ := b.releaseUpdate()
+	b.pending.Add(1)
+	go func() {
+		defer b.pending.Done()
+		callbackFunction(status)
+	}()
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) CheckReleaseUpdate(releaseUpdateCallbackFunction func(int, *lexactivator.Release, interface{}), releaseFlags uint, userData interface{}) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("CheckReleaseUpdate"); ok {
+		return status
+	}
+	if b.state.ReleaseVersion == "" {
+		return lexactivator.LA_E_RELEASE_VERSION
+	}
+	if b.state.ReleasePlatform == "" {
+		return lexactivator.LA_E_RELEASE_PLATFORM
+	}
+	if b.state.ReleaseChannel == "" {
+		return lexactivator.LA_E_RELEASE_CHANNEL
+	}
+	if releaseUpdateCallbackFunction == nil {
+		return lexactivator.LA_OK
+	}
+	status, release := b.releaseUpdate()
+	b.pending.Add(1)
+	go func() {
+		defer b.pending.Done()
+		releaseUpdateCallbackFunction(status, release, userData)
+	}()
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) releaseUpdate() (int, *lexactivator.Release) {
+	if b.state.Release == nil {
+		return lexactivator.LA_RELEASE_UPDATE_NOT_AVAILABLE, nil
+	}
+	release := *b.state.Release
+	return b.state.ReleaseStatus, &release
+}
+
+func (b *Backend) ActivateLicense() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("ActivateLicense"); ok {
+		return status
+	}
+	if status := b.checkLicenseKey(); status != lexactivator.LA_OK {
+		return status
+	}
+	b.activate(lexactivator.ModeOnline)
+	return b.state.License.Status
+}
+
+func (b *Backend) checkLicenseKey() int {
+	if b.state.LicenseKey == "" {
+		return lexactivator.LA_E_LICENSE_KEY
+	}
+	if b.state.License.Key != "" && b.state.License.Key != b.state.LicenseKey {
+		return lexactivator.LA_E_LICENSE_KEY
+	}
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) activate(mode lexactivator.Mode) {
+	if !b.state.Activated {
+		b.state.License.TotalActivations++
+	}
+	b.state.Activated = true
+	b.state.License.Key = b.state.LicenseKey
+	b.state.License.InitialMode = mode
+	b.state.License.CurrentMode = mode
+}
+
+func (b *Backend) deactivate() {
+	if b.state.Activated && b.state.License.TotalActivations > 0 {
+		b.state.License.TotalActivations--
+	}
+	b.state.Activated = false
+	b.state.ActivationMeterAttributeUses = map[string]uint{}
+}
+
+func (b *Backend) ActivateLicenseOffline(filePath string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("ActivateLicenseOffline"); ok {
+		return status
+	}
+	if status := readOfflineFile(filePath); status != lexactivator.LA_OK {
+		return status
+	}
+	if status := b.checkLicenseKey(); status != lexactivator.LA_OK {
+		return status
+	}
+	b.activate(lexactivator.ModeOffline)
+	return b.state.License.Status
+}
+
+func (b *Backend) GenerateOfflineActivationRequest(filePath string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("GenerateOfflineActivationRequest"); ok {
+		return status
+	}
+	if status := b.checkLicenseKey(); status != lexactivator.LA_OK {
+		return status
+	}
+	return b.writeOfflineRequest(filePath, "activation")
+}
+
+func (b *Backend) DeactivateLicense() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("DeactivateLicense"); ok {
+		return status
+	}
+	b.deactivate()
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) GenerateOfflineDeactivationRequest(filePath string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("GenerateOfflineDeactivationRequest"); ok {
+		return status
+	}
+	if status := b.writeOfflineRequest(filePath, "deactivation"); status != lexactivator.LA_OK {
+		return status
+	}
+	b.deactivate()
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) IsLicenseGenuine() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("IsLicenseGenuine"); ok {
+		return status
+	}
+	return b.state.License.Status
+}
+
+func (b *Backend) IsLicenseValid() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("IsLicenseValid"); ok {
+		return status
+	}
+	return b.state.License.Status
+}
+
+// trialCall is productCall for functions that need an activated trial.
+func (b *Backend) trialCall(method string) (int, bool) {
+	if status, ok := b.productCall(method); ok {
+		return status, true
+	}
+	if !b.state.Trial.Activated {
+		return lexactivator.LA_FAIL, true
+	}
+	return lexactivator.LA_OK, false
+}
+
+func (b *Backend) trialStatus() int {
+	if !b.state.Trial.ExpiryDate.IsZero() && !b.clock.Now().Before(b.state.Trial.ExpiryDate) {
+		return lexactivator.LA_TRIAL_EXPIRED
+	}
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) ActivateTrial() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("ActivateTrial"); ok {
+		return status
+	}
+	if !b.state.Trial.Allowed {
+		return lexactivator.LA_E_TRIAL_NOT_ALLOWED
+	}
+	b.state.Trial.Activated = true
+	return b.trialStatus()
+}
+
+func (b *Backend) ActivateTrialOffline(filePath string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("ActivateTrialOffline"); ok {
+		return status
+	}
+	if status := readOfflineFile(filePath); status != lexactivator.LA_OK {
+		return status
+	}
+	if !b.state.Trial.Allowed {
+		return lexactivator.LA_E_TRIAL_NOT_ALLOWED
+	}
+	b.state.Trial.Activated = true
+	return b.trialStatus()
+}
+
+func (b *Backend) GenerateOfflineTrialActivationRequest(filePath string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("GenerateOfflineTrialActivationRequest"); ok {
+		return status
+	}
+	return b.writeOfflineRequest(filePath, "trialActivation")
+}
+
+func (b *Backend) IsTrialGenuine() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.trialCall("IsTrialGenuine"); ok {
+		return status
+	}
+	return b.trialStatus()
+}
+
+func (b *Backend) localTrialStatus() int {
+	if !b.clock.Now().Before(b.state.LocalTrial.ExpiryDate) {
+		return lexactivator.LA_LOCAL_TRIAL_EXPIRED
+	}
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) ActivateLocalTrial(trialLength uint) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("ActivateLocalTrial"); ok {
+		return status
+	}
+	if !b.state.LocalTrial.Activated {
+		b.state.LocalTrial.Activated = true
+		b.state.LocalTrial.ExpiryDate = b.clock.Now().Add(time.Duration(trialLength) * 24 * time.Hour)
+	}
+	return b.localTrialStatus()
+}
+
+func (b *Backend) IsLocalTrialGenuine() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("IsLocalTrialGenuine"); ok {
+		return status
+	}
+	if !b.state.LocalTrial.Activated {
+		return lexactivator.LA_FAIL
+	}
+	return b.localTrialStatus()
+}
+
+func (b *Backend) ExtendLocalTrial(trialExtensionLength uint) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("ExtendLocalTrial"); ok {
+		return status
+	}
+	if !b.state.LocalTrial.Activated {
+		return lexactivator.LA_FAIL
+	}
+	b.state.LocalTrial.ExpiryDate = b.state.LocalTrial.ExpiryDate.Add(time.Duration(trialExtensionLength) * 24 * time.Hour)
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) IncrementActivationMeterAttributeUses(name string, increment uint) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("IncrementActivationMeterAttributeUses"); ok {
+		return status
+	}
+	attribute, ok := b.state.License.MeterAttributes[name]
+	if !ok {
+		return lexactivator.LA_E_METER_ATTRIBUTE_NOT_FOUND
+	}
+	if attribute.AllowedUses != 0 && attribute.TotalUses+increment > attribute.AllowedUses {
+		return lexactivator.LA_E_METER_ATTRIBUTE_USES_LIMIT_REACHED
+	}
+	attribute.TotalUses += increment
+	attribute.GrossUses += increment
+	b.state.License.MeterAttributes[name] = attribute
+	b.state.ActivationMeterAttributeUses[name] += increment
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) DecrementActivationMeterAttributeUses(name string, decrement uint) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("DecrementActivationMeterAttributeUses"); ok {
+		return status
+	}
+	attribute, ok := b.state.License.MeterAttributes[name]
+	if !ok {
+		return lexactivator.LA_E_METER_ATTRIBUTE_NOT_FOUND
+	}
+	if uses := b.state.ActivationMeterAttributeUses[name]; decrement > uses {
+		decrement = uses
+	}
+	attribute.TotalUses -= decrement
+	b.state.License.MeterAttributes[name] = attribute
+	b.state.ActivationMeterAttributeUses[name] -= decrement
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) ResetActivationMeterAttributeUses(name string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.licenseCall("ResetActivationMeterAttributeUses"); ok {
+		return status
+	}
+	attribute, ok := b.state.License.MeterAttributes[name]
+	if !ok {
+		return lexactivator.LA_E_METER_ATTRIBUTE_NOT_FOUND
+	}
+	attribute.TotalUses -= b.state.ActivationMeterAttributeUses[name]
+	b.state.License.MeterAttributes[name] = attribute
+	delete(b.state.ActivationMeterAttributeUses, name)
+	return lexactivator.LA_OK
+}
+
+func (b *Backend) Reset() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if status, ok := b.productCall("Reset"); ok {
+		return status
+	}
+	b.deactivate()
+	b.state.ActivationMetadata = map[string]string{}
+	b.state.Trial.Activated = false
+	b.state.LocalTrial = LocalTrial{}
+	return lexactivator.LA_OK
+}
+
+func lookup(values map[string]string, key string, value *string) int {
+	v, ok := values[key]
+	if !ok {
+		return lexactivator.LA_E_METADATA_KEY_NOT_FOUND
+	}
+	*value = v
+	return lexactivator.LA_OK
+}
+
+// offlineRequest is the content of the request files written by the
+// GenerateOffline*Request functions.
+type offlineRequest struct {
+	Type               string          `json:"type"`
+	ProductId          string          `json:"productId"`
+	LicenseKey         string          `json:"licenseKey,omitempty"`
+	MeterAttributeUses map[string]uint `json:"meterAttributeUses,omitempty"`
+}
+
+func (b *Backend) writeOfflineRequest(filePath string, requestType string) int {
+	if filePath == "" {
+		return lexactivator.LA_E_FILE_PATH
+	}
+	request := offlineRequest{
+		Type:               requestType,
+		ProductId:          b.state.ProductId,
+		LicenseKey:         b.state.LicenseKey,
+		MeterAttributeUses: copyUints(b.state.OfflineRequestMeterAttributeUses),
+	}
+	data, err := json.Marshal(request)
+	if err != nil {
+		return lexactivator.LA_FAIL
+	}
+	if err := ioutil.WriteFile(filePath, data, 0600); err != nil {
+		return lexactivator.LA_E_FILE_PERMISSION
+	}
+	return lexactivator.LA_OK
+}
+
+func readOfflineFile(filePath string) int {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return lexactivator.LA_E_FILE_PATH
+	}
+	if len(data) == 0 {
+		return lexactivator.LA_E_OFFLINE_RESPONSE_FILE
+	}
+	return lexactivator.LA_OK
+}
@@ -0,0 +1,38 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package fake
+
+import (
+	"testing"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+)
+
+// configure sets the product, the license key and the release of b.
+func configure(t *testing.T, b lexactivator.Backend) {
+	t.Helper()
+	for _, status := range []int{
+		b.SetProductData("data"),
+		b.SetProductId("id", lexactivator.LA_USER),
+		b.SetLicenseKey("key"),
+		b.SetReleaseVersion("1.0.0"),
+		b.SetReleasePlatform("linux"),
+		b.SetReleaseChannel("stable"),
+	} {
+		if status != lexactivator.LA_OK {
+			t.Fatalf("configuring the backend: %s", lexactivator.Status(status))
+		}
+	}
+}
+
+func TestCheckReleaseUpdateNilCallback(t *testing.T) {
+	b := New()
+	configure(t, b)
+	if status := b.CheckReleaseUpdate(nil, lexactivator.LA_RELEASES_ALL, nil); status != lexactivator.LA_OK {
+		t.Fatalf("CheckReleaseUpdate: %s", lexactivator.Status(status))
+	}
+	if status := b.CheckForReleaseUpdate("linux", "1.0.0", "stable", nil); status != lexactivator.LA_OK {
+		t.Fatalf("CheckForReleaseUpdate: %s", lexactivator.Status(status))
+	}
+	b.Wait()
+}
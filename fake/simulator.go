@@ -0,0 +1,414 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package fake
+
+import (
+	"time"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+)
+
+// Lifecycle configures the server side behaviour modelled by a Simulator.
+type Lifecycle struct {
+	// ServerSyncInterval is the interval at which IsLicenseGenuine() syncs
+	// the activation with the server. Zero disables server syncs.
+	ServerSyncInterval time.Duration
+
+	// GracePeriod is how long after a missed server sync the license keeps
+	// working before IsLicenseGenuine() returns LA_GRACE_PERIOD_OVER.
+	GracePeriod time.Duration
+
+	// TrialLength is the length of the verified trials started by
+	// ActivateTrial(), unless State.Trial.ExpiryDate is scripted.
+	TrialLength time.Duration
+
+	// ClockTolerance is how far the clock may go backwards before calls
+	// fail with LA_E_TIME_MODIFIED.
+	ClockTolerance time.Duration
+}
+
+// Simulator is a Backend that models the license lifecycle implemented by
+// LexActivator on top of the scripted State: activation limits, license
+// and trial expiry, suspension, revocation, server syncs with a grace
+// period, meter attribute limits and time tampering, all driven by an
+// injectable Clock.
+//
+//	clock := fake.NewManualClock(time.Now())
+//	sim := fake.NewSimulator(clock, fake.Lifecycle{
+//		ServerSyncInterval: time.Hour,
+//		GracePeriod:        72 * time.Hour,
+//	})
+//	...
+//	sim.SetOnline(false)
+//	clock.Advance(100 * time.Hour)
+//	status := sim.IsLicenseGenuine() // LA_GRACE_PERIOD_OVER
+type Simulator struct {
+	*Backend
+	lifecycle Lifecycle
+
+	// Server side state, guarded by Backend.mu.
+	online            bool
+	suspended         bool
+	revoked           bool
+	activationDeleted bool
+	lastSync          time.Time
+	lastSeen          time.Time
+}
+
+var _ lexactivator.Backend = (*Simulator)(nil)
+
+// NewSimulator returns a Simulator using clock that is online and has the
+// same initial state as New().
+func NewSimulator(clock Clock, lifecycle Lifecycle) *Simulator {
+	backend := New()
+	backend.SetClock(clock)
+	return &Simulator{
+		Backend:   backend,
+		lifecycle: lifecycle,
+		online:    true,
+		lastSeen:  clock.Now(),
+	}
+}
+
+// SetOnline sets whether the Cryptlex servers are reachable. Network bound
+// calls fail with LA_E_INET and server syncs are missed while offline.
+func (s *Simulator) SetOnline(online bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.online = online
+}
+
+// SetSuspended suspends or unsuspends the license on the server. The
+// activation learns about it on the next activation or server sync.
+func (s *Simulator) SetSuspended(suspended bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.suspended = suspended
+}
+
+// Revoke revokes the license on the server.
+func (s *Simulator) Revoke() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revoked = true
+}
+
+// DeleteActivation deletes the activation on the server, as done from the
+// dashboard.
+func (s *Simulator) DeleteActivation() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.activationDeleted = true
+}
+
+// Sync forces a server sync and invokes the license callback with its
+// result, regardless of the server sync interval.
+func (s *Simulator) Sync() {
+	s.mu.Lock()
+	status := lexactivator.LA_FAIL
+	if s.state.Activated {
+		status = s.serverSync(s.clock.Now())
+	}
+	s.mu.Unlock()
+	s.SyncWithStatus(status)
+}
+
+// observe records the current time and detects the clock going backwards.
+// It must be called with s.mu held.
+func (s *Simulator) observe() (time.Time, int) {
+	now := s.clock.Now()
+	if now.Before(s.lastSeen.Add(-s.lifecycle.ClockTolerance)) {
+		return now, lexactivator.LA_E_TIME_MODIFIED
+	}
+	if now.After(s.lastSeen) {
+		s.lastSeen = now
+	}
+	return now, lexactivator.LA_OK
+}
+
+// guard checks the time and, for network bound calls, the connectivity
+// before a call is passed on to the embedded Backend.
+func (s *Simulator) guard(network bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, status := s.observe(); status != lexactivator.LA_OK {
+		return status
+	}
+	if network && !s.online {
+		return lexactivator.LA_E_INET
+	}
+	return lexactivator.LA_OK
+}
+
+// licenseStatus returns the status of the local activation at now. It must
+// be called with s.mu held.
+func (s *Simulator) licenseStatus(now time.Time) int {
+	expiry := s.state.License.ExpiryDate
+	if !expiry.IsZero() && !now.Before(expiry) {
+		return lexactivator.LA_EXPIRED
+	}
+	return s.state.License.Status
+}
+
+// serverStatus returns the status the server reports for the license.
+func (s *Simulator) serverStatus() int {
+	if s.suspended {
+		return lexactivator.LA_SUSPENDED
+	}
+	return lexactivator.LA_OK
+}
+
+func (s *Simulator) graceExpiry() time.Time {
+	return s.lastSync.Add(s.lifecycle.ServerSyncInterval + s.lifecycle.GracePeriod)
+}
+
+func (s *Simulator) graceOver(now time.Time) bool {
+	return s.lifecycle.ServerSyncInterval > 0 && !now.Before(s.graceExpiry())
+}
+
+// serverSync syncs the activation with the server and returns the status
+// passed to the license callback. It must be called with s.mu held.
+func (s *Simulator) serverSync(now time.Time) int {
+	if !s.online {
+		return lexactivator.LA_E_INET
+	}
+	s.lastSync = now
+	switch {
+	case s.revoked:
+		s.deactivate()
+		return lexactivator.LA_E_REVOKED
+	case s.activationDeleted:
+		s.deactivate()
+		return lexactivator.LA_E_ACTIVATION_NOT_FOUND
+	}
+	s.state.License.Status = s.serverStatus()
+	return s.licenseStatus(now)
+}
+
+// activateLicense activates the license with the server state applied. It
+// must be called with s.mu held.
+func (s *Simulator) activateLicense(now time.Time, mode lexactivator.Mode) int {
+	if status := s.checkLicenseKey(); status != lexactivator.LA_OK {
+		return status
+	}
+	if s.revoked {
+		return lexactivator.LA_E_REVOKED
+	}
+	license := s.state.License
+	if !s.state.Activated && license.AllowedActivations != 0 && license.TotalActivations >= license.AllowedActivations {
+		return lexactivator.LA_E_ACTIVATION_LIMIT
+	}
+	s.activate(mode)
+	s.activationDeleted = false
+	s.lastSync = now
+	s.state.License.Status = s.serverStatus()
+	return s.licenseStatus(now)
+}
+
+func (s *Simulator) ActivateLicense() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if status, ok := s.productCall("ActivateLicense"); ok {
+		return status
+	}
+	now, status := s.observe()
+	if status != lexactivator.LA_OK {
+		return status
+	}
+	if !s.online {
+		return lexactivator.LA_E_INET
+	}
+	return s.activateLicense(now, lexactivator.ModeOnline)
+}
+
+func (s *Simulator) ActivateLicenseOffline(filePath string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if status, ok := s.productCall("ActivateLicenseOffline"); ok {
+		return status
+	}
+	now, status := s.observe()
+	if status != lexactivator.LA_OK {
+		return status
+	}
+	if status := readOfflineFile(filePath); status != lexactivator.LA_OK {
+		return status
+	}
+	return s.activateLicense(now, lexactivator.ModeOffline)
+}
+
+func (s *Simulator) DeactivateLicense() int {
+	if status := s.guard(true); status != lexactivator.LA_OK {
+		return status
+	}
+	return s.Backend.DeactivateLicense()
+}
+
+func (s *Simulator) IsLicenseGenuine() int {
+	status, synced := s.isLicenseGenuine()
+	if synced {
+		s.SyncWithStatus(status)
+	}
+	return status
+}
+
+func (s *Simulator) isLicenseGenuine() (status int, synced bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if status, ok := s.licenseCall("IsLicenseGenuine"); ok {
+		return status, false
+	}
+	now, status := s.observe()
+	if status != lexactivator.LA_OK {
+		return status, false
+	}
+	interval := s.lifecycle.ServerSyncInterval
+	if interval <= 0 || now.Before(s.lastSync.Add(interval)) {
+		return s.licenseStatus(now), false
+	}
+	status = s.serverSync(now)
+	if status == lexactivator.LA_E_INET && s.graceOver(now) {
+		return lexactivator.LA_GRACE_PERIOD_OVER, true
+	}
+	if status == lexactivator.LA_E_INET {
+		return s.licenseStatus(now), true
+	}
+	return status, true
+}
+
+func (s *Simulator) IsLicenseValid() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if status, ok := s.licenseCall("IsLicenseValid"); ok {
+		return status
+	}
+	now, status := s.observe()
+	if status != lexactivator.LA_OK {
+		return status
+	}
+	if s.graceOver(now) {
+		return lexactivator.LA_GRACE_PERIOD_OVER
+	}
+	return s.licenseStatus(now)
+}
+
+func (s *Simulator) GetServerSyncGracePeriodExpiryDate(expiryDate *uint) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if status, ok := s.licenseCall("GetServerSyncGracePeriodExpiryDate"); ok {
+		return status
+	}
+	*expiryDate = 0
+	if s.lifecycle.ServerSyncInterval > 0 {
+		*expiryDate = unixTimestamp(s.graceExpiry())
+	}
+	return lexactivator.LA_OK
+}
+
+func (s *Simulator) ActivateTrial() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if status, ok := s.productCall("ActivateTrial"); ok {
+		return status
+	}
+	now, status := s.observe()
+	if status != lexactivator.LA_OK {
+		return status
+	}
+	if !s.online {
+		return lexactivator.LA_E_INET
+	}
+	return s.activateTrial(now)
+}
+
+func (s *Simulator) ActivateTrialOffline(filePath string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if status, ok := s.productCall("ActivateTrialOffline"); ok {
+		return status
+	}
+	now, status := s.observe()
+	if status != lexactivator.LA_OK {
+		return status
+	}
+	if status := readOfflineFile(filePath); status != lexactivator.LA_OK {
+		return status
+	}
+	return s.activateTrial(now)
+}
+
+func (s *Simulator) activateTrial(now time.Time) int {
+	if !s.state.Trial.Allowed {
+		return lexactivator.LA_E_TRIAL_NOT_ALLOWED
+	}
+	if !s.state.Trial.Activated {
+		s.state.Trial.Activated = true
+		if s.state.Trial.ExpiryDate.IsZero() && s.lifecycle.TrialLength > 0 {
+			s.state.Trial.ExpiryDate = now.Add(s.lifecycle.TrialLength)
+		}
+	}
+	return s.trialStatus()
+}
+
+func (s *Simulator) IsTrialGenuine() int {
+	if status := s.guard(false); status != lexactivator.LA_OK {
+		return status
+	}
+	return s.Backend.IsTrialGenuine()
+}
+
+func (s *Simulator) ActivateLocalTrial(trialLength uint) int {
+	if status := s.guard(false); status != lexactivator.LA_OK {
+		return status
+	}
+	return s.Backend.ActivateLocalTrial(trialLength)
+}
+
+func (s *Simulator) IsLocalTrialGenuine() int {
+	if status := s.guard(false); status != lexactivator.LA_OK {
+		return status
+	}
+	return s.Backend.IsLocalTrialGenuine()
+}
+
+func (s *Simulator) ExtendLocalTrial(trialExtensionLength uint) int {
+	if status := s.guard(false); status != lexactivator.LA_OK {
+		return status
+	}
+	return s.Backend.ExtendLocalTrial(trialExtensionLength)
+}
+
+func (s *Simulator) IncrementActivationMeterAttributeUses(name string, increment uint) int {
+	if status := s.guard(true); status != lexactivator.LA_OK {
+		return status
+	}
+	return s.Backend.IncrementActivationMeterAttributeUses(name, increment)
+}
+
+func (s *Simulator) DecrementActivationMeterAttributeUses(name string, decrement uint) int {
+	if status := s.guard(true); status != lexactivator.LA_OK {
+		return status
+	}
+	return s.Backend.DecrementActivationMeterAttributeUses(name, decrement)
+}
+
+func (s *Simulator) ResetActivationMeterAttributeUses(name string) int {
+	if status := s.guard(true); status != lexactivator.LA_OK {
+		return status
+	}
+	return s.Backend.ResetActivationMeterAttributeUses(name)
+}
+
+func (s *Simulator) CheckForReleaseUpdate(platform string, version string, channel string, callbackFunction func(int)) int {
+	if status := s.guard(true); status != lexactivator.LA_OK {
+		return status
+	}
+	return s.Backend.CheckForReleaseUpdate(platform, version, channel, callbackFunction)
+}
+
+func (s *Simulator) CheckReleaseUpdate(releaseUpdateCallbackFunction func(int, *lexactivator.Release, interface{}), releaseFlags uint, userData interface{}) int {
+	if status := s.guard(true); status != lexactivator.LA_OK {
+		return status
+	}
+	return s.Backend.CheckReleaseUpdate(releaseUpdateCallbackFunction, releaseFlags, userData)
+}
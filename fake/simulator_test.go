@@ -0,0 +1,146 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package fake
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+)
+
+var start = time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// newActivated returns a simulator with an activated license.
+func newActivated(t *testing.T, lifecycle Lifecycle, script func(s *State)) (*Simulator, *ManualClock) {
+	t.Helper()
+	clock := NewManualClock(start)
+	sim := NewSimulator(clock, lifecycle)
+	configure(t, sim)
+	if script != nil {
+		sim.Update(script)
+	}
+	if status := sim.ActivateLicense(); status != lexactivator.LA_OK {
+		t.Fatalf("ActivateLicense: %s", lexactivator.Status(status))
+	}
+	return sim, clock
+}
+
+func expect(t *testing.T, call string, status, want int) {
+	t.Helper()
+	if status != want {
+		t.Fatalf("%s: %s, want %s", call, lexactivator.Status(status), lexactivator.Status(want))
+	}
+}
+
+func TestSimulatorLicenseExpiry(t *testing.T) {
+	sim, clock := newActivated(t, Lifecycle{}, func(s *State) {
+		s.License.ExpiryDate = start.Add(24 * time.Hour)
+	})
+	expect(t, "IsLicenseGenuine", sim.IsLicenseGenuine(), lexactivator.LA_OK)
+	clock.Advance(24 * time.Hour)
+	expect(t, "IsLicenseGenuine after expiry", sim.IsLicenseGenuine(), lexactivator.LA_EXPIRED)
+	expect(t, "IsLicenseValid after expiry", sim.IsLicenseValid(), lexactivator.LA_EXPIRED)
+}
+
+func TestSimulatorGracePeriod(t *testing.T) {
+	sim, clock := newActivated(t, Lifecycle{ServerSyncInterval: time.Hour, GracePeriod: 72 * time.Hour}, nil)
+	var expiry uint
+	expect(t, "GetServerSyncGracePeriodExpiryDate", sim.GetServerSyncGracePeriodExpiryDate(&expiry), lexactivator.LA_OK)
+	if want := start.Add(73 * time.Hour); expiry != uint(want.Unix()) {
+		t.Fatalf("grace period expiry %d, want %d", expiry, want.Unix())
+	}
+
+	sim.SetOnline(false)
+	clock.Advance(2 * time.Hour)
+	expect(t, "IsLicenseGenuine offline", sim.IsLicenseGenuine(), lexactivator.LA_OK)
+	clock.Advance(71 * time.Hour)
+	expect(t, "IsLicenseGenuine after the grace period", sim.IsLicenseGenuine(), lexactivator.LA_GRACE_PERIOD_OVER)
+	expect(t, "IsLicenseValid after the grace period", sim.IsLicenseValid(), lexactivator.LA_GRACE_PERIOD_OVER)
+
+	sim.SetOnline(true)
+	expect(t, "IsLicenseGenuine online", sim.IsLicenseGenuine(), lexactivator.LA_OK)
+	expect(t, "IsLicenseValid online", sim.IsLicenseValid(), lexactivator.LA_OK)
+}
+
+func TestSimulatorSuspension(t *testing.T) {
+	sim, clock := newActivated(t, Lifecycle{ServerSyncInterval: time.Hour}, nil)
+	var mu sync.Mutex
+	var synced []int
+	sim.SetLicenseCallback(func(status int) {
+		mu.Lock()
+		defer mu.Unlock()
+		synced = append(synced, status)
+	})
+
+	sim.SetSuspended(true)
+	expect(t, "IsLicenseGenuine before the server sync", sim.IsLicenseGenuine(), lexactivator.LA_OK)
+	clock.Advance(time.Hour)
+	expect(t, "IsLicenseGenuine after the server sync", sim.IsLicenseGenuine(), lexactivator.LA_SUSPENDED)
+	sim.Wait()
+	if len(synced) != 1 || synced[0] != lexactivator.LA_SUSPENDED {
+		t.Fatalf("license callback invoked with %v", synced)
+	}
+
+	sim.SetSuspended(false)
+	sim.Sync()
+	sim.Wait()
+	expect(t, "IsLicenseValid after unsuspension", sim.IsLicenseValid(), lexactivator.LA_OK)
+}
+
+func TestSimulatorRevocation(t *testing.T) {
+	sim, _ := newActivated(t, Lifecycle{ServerSyncInterval: time.Hour}, nil)
+	sim.Revoke()
+	sim.Sync()
+	sim.Wait()
+	if sim.State().Activated {
+		t.Fatal("revoked license still activated")
+	}
+	expect(t, "ActivateLicense after revocation", sim.ActivateLicense(), lexactivator.LA_E_REVOKED)
+}
+
+func TestSimulatorTrialExpiry(t *testing.T) {
+	clock := NewManualClock(start)
+	sim := NewSimulator(clock, Lifecycle{TrialLength: 7 * 24 * time.Hour})
+	configure(t, sim)
+	expect(t, "ActivateTrial", sim.ActivateTrial(), lexactivator.LA_OK)
+	clock.Advance(7*24*time.Hour - time.Second)
+	expect(t, "IsTrialGenuine", sim.IsTrialGenuine(), lexactivator.LA_OK)
+	clock.Advance(time.Second)
+	expect(t, "IsTrialGenuine after expiry", sim.IsTrialGenuine(), lexactivator.LA_TRIAL_EXPIRED)
+	expect(t, "ActivateTrial after expiry", sim.ActivateTrial(), lexactivator.LA_TRIAL_EXPIRED)
+}
+
+func TestSimulatorClockTampering(t *testing.T) {
+	sim, clock := newActivated(t, Lifecycle{ClockTolerance: time.Minute}, nil)
+	clock.Advance(-time.Minute)
+	expect(t, "IsLicenseGenuine within the tolerance", sim.IsLicenseGenuine(), lexactivator.LA_OK)
+	clock.Advance(-time.Second)
+	expect(t, "IsLicenseGenuine with the clock set back", sim.IsLicenseGenuine(), lexactivator.LA_E_TIME_MODIFIED)
+	clock.Set(start)
+	expect(t, "IsLicenseGenuine with the clock restored", sim.IsLicenseGenuine(), lexactivator.LA_OK)
+}
+
+func TestSimulatorOffline(t *testing.T) {
+	sim, _ := newActivated(t, Lifecycle{}, func(s *State) {
+		s.License.MeterAttributes["exports"] = lexactivator.MeterAttribute{AllowedUses: 10}
+	})
+	sim.SetOnline(false)
+	expect(t, "IncrementActivationMeterAttributeUses offline", sim.IncrementActivationMeterAttributeUses("exports", 1), lexactivator.LA_E_INET)
+	expect(t, "DeactivateLicense offline", sim.DeactivateLicense(), lexactivator.LA_E_INET)
+	sim.SetOnline(true)
+	expect(t, "IncrementActivationMeterAttributeUses online", sim.IncrementActivationMeterAttributeUses("exports", 1), lexactivator.LA_OK)
+}
+
+func TestManualClock(t *testing.T) {
+	clock := NewManualClock(start)
+	clock.Advance(time.Hour)
+	if now := clock.Now(); !now.Equal(start.Add(time.Hour)) {
+		t.Fatalf("Now() after Advance: %v", now)
+	}
+	clock.Set(start)
+	if now := clock.Now(); !now.Equal(start) {
+		t.Fatalf("Now() after Set: %v", now)
+	}
+}
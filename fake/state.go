@@ -0,0 +1,216 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package fake
+
+import (
+	"time"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+)
+
+// State is the scripted state of a fake Backend. Tests modify it through
+// Backend.Update() and read it through Backend.State().
+type State struct {
+	ProductFile   string
+	ProductData   string
+	ProductId     string
+	DataDirectory string
+
+	// Product and product version details.
+	ProductMetadata           map[string]string
+	ProductVersionName        string
+	ProductVersionDisplayName string
+	FeatureFlags              map[string]lexactivator.FeatureFlag
+
+	LibraryVersion string
+
+	// License is the license the key set by SetLicenseKey() activates.
+	License License
+
+	// Activated is true once ActivateLicense() or ActivateLicenseOffline()
+	// succeeded and until DeactivateLicense() or Reset() is called.
+	Activated bool
+
+	// ActivationMetadata holds the metadata set by SetActivationMetadata().
+	ActivationMetadata map[string]string
+
+	// ActivationMeterAttributeUses holds the uses consumed by this
+	// activation per meter attribute.
+	ActivationMeterAttributeUses map[string]uint
+
+	// OfflineRequestMeterAttributeUses holds the uses set by
+	// SetOfflineActivationRequestMeterAttributeUses().
+	OfflineRequestMeterAttributeUses map[string]uint
+
+	Trial      Trial
+	LocalTrial LocalTrial
+
+	// Release returned to CheckReleaseUpdate() callbacks along with
+	// ReleaseStatus. A nil Release is reported as
+	// LA_RELEASE_UPDATE_NOT_AVAILABLE.
+	Release       *lexactivator.Release
+	ReleaseStatus int
+
+	// Values recorded by the corresponding setters.
+	LicenseKey           string
+	UserEmail            string
+	UserPassword         string
+	CustomFingerprint    string
+	LeaseDuration        uint
+	AppVersion           string
+	ReleaseVersion       string
+	ReleasePublishedDate uint
+	ReleasePlatform      string
+	ReleaseChannel       string
+	NetworkProxy         string
+	CryptlexHost         string
+}
+
+// License is the license known to the fake server.
+type License struct {
+	// Key is the only key ActivateLicense() accepts. An empty Key accepts
+	// any non-empty license key.
+	Key string
+
+	// Status returned by ActivateLicense(), IsLicenseGenuine() and
+	// IsLicenseValid() once activated, e.g. LA_OK, LA_EXPIRED or
+	// LA_SUSPENDED.
+	Status int
+
+	Type                     string
+	ExpiryDate               time.Time
+	MaintenanceExpiryDate    time.Time
+	GracePeriodExpiryDate    time.Time
+	AllowedActivations       uint
+	TotalActivations         uint
+	MaxAllowedReleaseVersion string
+	Metadata                 map[string]string
+	MeterAttributes          map[string]lexactivator.MeterAttribute
+
+	UserEmail           string
+	UserName            string
+	UserCompany         string
+	UserMetadata        map[string]string
+	OrganizationName    string
+	OrganizationAddress lexactivator.OrganizationAddress
+
+	InitialMode lexactivator.Mode
+	CurrentMode lexactivator.Mode
+}
+
+// Trial is the verified trial of the product.
+type Trial struct {
+	// Allowed is false when the product does not allow trials.
+	Allowed bool
+
+	Activated  bool
+	Id         string
+	ExpiryDate time.Time
+	Metadata   map[string]string
+}
+
+// LocalTrial is the unverified local trial of the product.
+type LocalTrial struct {
+	Activated  bool
+	ExpiryDate time.Time
+}
+
+func newState() State {
+	return State{
+		ProductMetadata:                  map[string]string{},
+		FeatureFlags:                     map[string]lexactivator.FeatureFlag{},
+		LibraryVersion:                   "3.0.0",
+		ActivationMetadata:               map[string]string{},
+		ActivationMeterAttributeUses:     map[string]uint{},
+		OfflineRequestMeterAttributeUses: map[string]uint{},
+		ReleaseStatus:                    lexactivator.LA_RELEASE_UPDATE_NOT_AVAILABLE,
+		License: License{
+			Status:             lexactivator.LA_OK,
+			Type:               "node-locked",
+			AllowedActivations: 1,
+			Metadata:           map[string]string{},
+			MeterAttributes:    map[string]lexactivator.MeterAttribute{},
+			UserMetadata:       map[string]string{},
+			InitialMode:        lexactivator.ModeOnline,
+			CurrentMode:        lexactivator.ModeOnline,
+		},
+		Trial: Trial{
+			Allowed:  true,
+			Id:       "trial-id",
+			Metadata: map[string]string{},
+		},
+	}
+}
+
+// clone returns a deep copy of the state so that snapshots handed out by
+// Backend.State() do not share maps with the backend.
+func (s State) clone() State {
+	c := s
+	c.ProductMetadata = copyStrings(s.ProductMetadata)
+	c.FeatureFlags = map[string]lexactivator.FeatureFlag{}
+	for k, v := range s.FeatureFlags {
+		c.FeatureFlags[k] = v
+	}
+	c.ActivationMetadata = copyStrings(s.ActivationMetadata)
+	c.ActivationMeterAttributeUses = copyUints(s.ActivationMeterAttributeUses)
+	c.OfflineRequestMeterAttributeUses = copyUints(s.OfflineRequestMeterAttributeUses)
+	c.License.Metadata = copyStrings(s.License.Metadata)
+	c.License.UserMetadata = copyStrings(s.License.UserMetadata)
+	c.License.MeterAttributes = map[string]lexactivator.MeterAttribute{}
+	for k, v := range s.License.MeterAttributes {
+		c.License.MeterAttributes[k] = v
+	}
+	c.Trial.Metadata = copyStrings(s.Trial.Metadata)
+	if s.Release != nil {
+		release := *s.Release
+		c.Release = &release
+	}
+	return c
+}
+
+// normalize allocates the maps a script may have left nil.
+func (s *State) normalize() {
+	if s.ProductMetadata == nil {
+		s.ProductMetadata = map[string]string{}
+	}
+	if s.FeatureFlags == nil {
+		s.FeatureFlags = map[string]lexactivator.FeatureFlag{}
+	}
+	if s.ActivationMetadata == nil {
+		s.ActivationMetadata = map[string]string{}
+	}
+	if s.ActivationMeterAttributeUses == nil {
+		s.ActivationMeterAttributeUses = map[string]uint{}
+	}
+	if s.OfflineRequestMeterAttributeUses == nil {
+		s.OfflineRequestMeterAttributeUses = map[string]uint{}
+	}
+	if s.License.Metadata == nil {
+		s.License.Metadata = map[string]string{}
+	}
+	if s.License.UserMetadata == nil {
+		s.License.UserMetadata = map[string]string{}
+	}
+	if s.License.MeterAttributes == nil {
+		s.License.MeterAttributes = map[string]lexactivator.MeterAttribute{}
+	}
+	if s.Trial.Metadata == nil {
+		s.Trial.Metadata = map[string]string{}
+	}
+}
+
+func copyStrings(m map[string]string) map[string]string {
+	c := make(map[string]string, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func copyUints(m map[string]uint) map[string]uint {
+	c := make(map[string]uint, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
@@ -0,0 +1,221 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+// Package features answers feature flag queries from a cache of the product
+// version feature flags, so that checking a flag on a hot path does not
+// call into the native library:
+//
+//	flags := features.New(client, "export", "sso")
+//	if err := flags.Load(); err != nil {
+//		...
+//	}
+//	if err := flags.Watch(ctx, nil); err != nil {
+//		...
+//	}
+//	...
+//	if flags.Enabled("export") {
+//		...
+//	}
+package features
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+)
+
+// Reason explains why a flag is disabled.
+type Reason string
+
+const (
+	// ReasonOff is the reason of a flag turned off for the product version.
+	ReasonOff Reason = "turned off for the product version"
+
+	// ReasonNotLinked is the reason of every flag when no product version
+	// is linked to the license (LA_E_PRODUCT_VERSION_NOT_LINKED).
+	ReasonNotLinked Reason = "no product version linked to the license"
+
+	// ReasonNotFound is the reason of a flag the product version does not
+	// have (LA_E_FEATURE_FLAG_NOT_FOUND).
+	ReasonNotFound Reason = "not defined for the product version"
+
+	// ReasonError is the reason of a flag that could not be read, see
+	// Flag.Err.
+	ReasonError Reason = "could not be read"
+
+	// ReasonNotLoaded is the reason of every flag before Load().
+	ReasonNotLoaded Reason = "not loaded yet"
+
+	// ReasonUnknown is the reason of a flag not passed to New().
+	ReasonUnknown Reason = "not a known flag"
+)
+
+// Flag is a cached feature flag.
+type Flag struct {
+	lexactivator.FeatureFlag
+
+	// Reason explains why the flag is disabled. It is empty for an enabled
+	// flag.
+	Reason Reason
+
+	// Err is the error reading the flag, with ReasonError.
+	Err error
+}
+
+// DisabledError is returned by Decode() for a disabled flag.
+type DisabledError struct {
+	Name   string
+	Reason Reason
+	Err    error
+}
+
+func (e *DisabledError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("features: %s is disabled: %s: %v", e.Name, e.Reason, e.Err)
+	}
+	return fmt.Sprintf("features: %s is disabled: %s", e.Name, e.Reason)
+}
+
+func (e *DisabledError) Unwrap() error {
+	return e.Err
+}
+
+// Features caches the feature flags of the product version linked to the
+// license. The cache is replaced as a whole by Load(), so readers always
+// see the flags of a single load. It is safe for concurrent use.
+type Features struct {
+	client *lexactivator.Client
+	names  []string
+
+	// loadMu serializes the loads, so that a slower load cannot replace
+	// the flags of a later one.
+	loadMu sync.Mutex
+
+	// flags holds the map[string]Flag of the last load.
+	flags atomic.Value
+}
+
+// New returns the cache of the named flags, read through client. The
+// flags are read by Load().
+func New(client *lexactivator.Client, names ...string) *Features {
+	f := &Features{client: client, names: append([]string(nil), names...)}
+	f.flags.Store(map[string]Flag(nil))
+	return f
+}
+
+// Load reads every known flag with GetProductVersionFeatureFlag() and
+// replaces the cache. LA_E_PRODUCT_VERSION_NOT_LINKED and
+// LA_E_FEATURE_FLAG_NOT_FOUND disable the flag with ReasonNotLinked and
+// ReasonNotFound; other errors disable it with ReasonError, and the first
+// one is returned.
+func (f *Features) Load() error {
+	f.loadMu.Lock()
+	defer f.loadMu.Unlock()
+	flags := make(map[string]Flag, len(f.names))
+	var first error
+	for _, name := range f.names {
+		flag, err := f.client.ProductVersionFeatureFlag(name)
+		entry := Flag{FeatureFlag: flag}
+		switch {
+		case err == nil:
+			if !flag.Enabled {
+				entry.Reason = ReasonOff
+			}
+		case errors.Is(err, lexactivator.ErrProductVersionNotLinked):
+			entry.Reason = ReasonNotLinked
+		case errors.Is(err, lexactivator.ErrFeatureFlagNotFound):
+			entry.Reason = ReasonNotFound
+		default:
+			entry.Reason, entry.Err = ReasonError, err
+			if first == nil {
+				first = fmt.Errorf("features: reading %s: %w", name, err)
+			}
+		}
+		if entry.Reason != "" {
+			entry.Enabled = false
+		}
+		flags[name] = entry
+	}
+	f.flags.Store(flags)
+	return first
+}
+
+// Watch subscribes to the license events of the client and reloads the
+// flags in the background after every successful server sync, reporting
+// LA_OK, LA_EXPIRED or LA_SUSPENDED, until ctx is done. The errors of
+// Load() are passed to onError, which may be nil. It returns the error of
+// subscribing, see LicenseEvents().
+func (f *Features) Watch(ctx context.Context, onError func(error)) error {
+	events, err := f.client.LicenseEvents(ctx)
+	if err != nil {
+		return err
+	}
+	go func() {
+		for event := range events {
+			switch int(event.Status) {
+			case lexactivator.LA_OK, lexactivator.LA_EXPIRED, lexactivator.LA_SUSPENDED:
+			default:
+				continue
+			}
+			if err := f.Load(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}()
+	return nil
+}
+
+// Enabled reports whether the flag is enabled. Unknown flags, flags that
+// could not be read and all flags before Load() are disabled.
+func (f *Features) Enabled(name string) bool {
+	return f.Flag(name).Enabled
+}
+
+// Flag returns the cached flag, with the reason it is disabled if it is.
+func (f *Features) Flag(name string) Flag {
+	flags := f.flags.Load().(map[string]Flag)
+	if flags == nil {
+		return Flag{FeatureFlag: lexactivator.FeatureFlag{Name: name}, Reason: ReasonNotLoaded}
+	}
+	flag, ok := flags[name]
+	if !ok {
+		return Flag{FeatureFlag: lexactivator.FeatureFlag{Name: name}, Reason: ReasonUnknown}
+	}
+	return flag
+}
+
+// Flags returns the cached flags by name, nil before Load().
+func (f *Features) Flags() map[string]Flag {
+	flags := f.flags.Load().(map[string]Flag)
+	if flags == nil {
+		return nil
+	}
+	result := make(map[string]Flag, len(flags))
+	for name, flag := range flags {
+		result[name] = flag
+	}
+	return result
+}
+
+// Decode decodes the JSON data of an enabled flag into v. It returns a
+// *DisabledError for a disabled flag and a *lexactivator.DecodeError for
+// data that does not decode into v.
+func (f *Features) Decode(name string, v interface{}) error {
+	flag := f.Flag(name)
+	if !flag.Enabled {
+		return &DisabledError{Name: name, Reason: flag.Reason, Err: flag.Err}
+	}
+	if err := json.Unmarshal([]byte(flag.Data), v); err != nil {
+		return &lexactivator.DecodeError{
+			Type: strings.TrimPrefix(fmt.Sprintf("%T", v), "*"),
+			JSON: flag.Data,
+			Err:  err,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package features
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+	"github.com/Exostellar/lexactivator-go/fake"
+)
+
+// newFeatures returns the cache of the flags "export", "sso" and "theme" of a
+// fake backend with an activated license linked to a product version with
+// the flags "export", turned off, and "theme".
+func newFeatures(t *testing.T) (*Features, *fake.Backend) {
+	t.Helper()
+	b := fake.New()
+	b.Update(func(s *fake.State) {
+		s.ProductVersionName = "pro"
+		s.FeatureFlags["export"] = lexactivator.FeatureFlag{Name: "export"}
+		s.FeatureFlags["theme"] = lexactivator.FeatureFlag{Name: "theme", Enabled: true, Data: `{"color":"blue"}`}
+	})
+	client, err := lexactivator.New(lexactivator.Config{Backend: b, ProductData: "data", ProductId: "id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetLicenseKey("key"); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.ActivateLicense(); err != nil {
+		t.Fatal(err)
+	}
+	return New(client, "export", "sso", "theme"), b
+}
+
+func setEnabled(b *fake.Backend, name string, enabled bool) {
+	b.Update(func(s *fake.State) {
+		flag := s.FeatureFlags[name]
+		flag.Enabled = enabled
+		s.FeatureFlags[name] = flag
+	})
+}
+
+func TestLoad(t *testing.T) {
+	features, b := newFeatures(t)
+	if flag := features.Flag("theme"); flag.Enabled || flag.Reason != ReasonNotLoaded {
+		t.Fatalf("flag before Load(): %+v", flag)
+	}
+	if err := features.Load(); err != nil {
+		t.Fatal(err)
+	}
+	reasons := map[string]Reason{"export": ReasonOff, "sso": ReasonNotFound, "theme": "", "other": ReasonUnknown}
+	for name, reason := range reasons {
+		if flag := features.Flag(name); flag.Reason != reason || flag.Enabled != (reason == "") {
+			t.Errorf("%s: %+v, want reason %q", name, flag, reason)
+		}
+	}
+	var theme struct{ Color string }
+	if err := features.Decode("theme", &theme); err != nil || theme.Color != "blue" {
+		t.Fatalf("decoded %+v, %v", theme, err)
+	}
+	var disabled *DisabledError
+	if err := features.Decode("export", &theme); !errors.As(err, &disabled) || disabled.Reason != ReasonOff {
+		t.Fatalf("error %v, want a *DisabledError", err)
+	}
+
+	b.Update(func(s *fake.State) { s.ProductVersionName = "" })
+	if err := features.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if flag := features.Flag("theme"); flag.Enabled || flag.Reason != ReasonNotLinked {
+		t.Fatalf("flag without a product version: %+v", flag)
+	}
+
+	b.Fail("GetProductVersionFeatureFlag", lexactivator.LA_E_LICENSE_KEY)
+	if err := features.Load(); !errors.Is(err, lexactivator.ErrLicenseKey) {
+		t.Fatalf("error %v, want ErrLicenseKey", err)
+	}
+	if flag := features.Flag("theme"); flag.Enabled || flag.Reason != ReasonError || flag.Err == nil {
+		t.Fatalf("flag that could not be read: %+v", flag)
+	}
+}
+
+func TestLoadConcurrent(t *testing.T) {
+	features, b := newFeatures(t)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(enabled bool) {
+			defer wg.Done()
+			setEnabled(b, "export", enabled)
+			features.Load()
+			features.Enabled("export")
+		}(i%2 == 0)
+	}
+	wg.Wait()
+	setEnabled(b, "export", true)
+	if err := features.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if !features.Enabled("export") {
+		t.Fatal("the last load did not replace the flags")
+	}
+}
+
+func TestWatch(t *testing.T) {
+	features, b := newFeatures(t)
+	if err := features.Load(); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := features.Watch(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	setEnabled(b, "export", true)
+	// A failed server sync does not reload the flags, the successful one
+	// after it does.
+	b.SyncWithStatus(lexactivator.LA_E_INET)
+	b.Wait()
+	b.SyncWithStatus(lexactivator.LA_OK)
+	for deadline := time.Now().Add(5 * time.Second); !features.Enabled("export"); {
+		if time.Now().After(deadline) {
+			t.Fatal("the flags were not reloaded after the server sync")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if calls := b.Calls("GetProductVersionFeatureFlag"); calls != 6 {
+		t.Fatalf("%d flags read, want 6 for two loads", calls)
+	}
+}
@@ -1,5 +1,8 @@
 // Copyright 2023 Cryptlex, LLC. All rights reserved.
 
+//go:build cgo && !lexactivator_stub
+// +build cgo,!lexactivator_stub
+
 package lexactivator
 
 /*
@@ -9,64 +12,30 @@ package lexactivator
 #cgo windows LDFLAGS: -L${SRCDIR}/libs/windows_amd64 -lLexActivator
 #include "LexActivator.h"
 #include <stdlib.h>
+#include <stdint.h>
 void licenseCallbackCgoGateway(int status);
 void releaseUpdateCallbackCgoGateway(int status);
-void newReleaseUpdateCallbackCgoGateway(int status, char* releaseJson, void* unused);
+void newReleaseUpdateCallbackCgoGateway(int status, char* releaseJson, void* userData);
+static inline void* releaseCheckUserData(uintptr_t id) { return (void*)id; }
 */
 import "C"
 import (
-	"encoding/json"
 	"unsafe"
 )
 
-type callbackType func(int)
-type releaseCallbackType func(int, *Release, interface{})
-
-const (
-	LA_USER      uint = 1
-	LA_SYSTEM    uint = 2
-	LA_IN_MEMORY uint = 4
-)
-
-const (
-   LA_RELEASES_ALL     uint = 1
-   LA_RELEASES_ALLOWED uint = 2
-)
-
-var licenseCallbackFuncion callbackType
-
-var legacyReleaseCallbackFunction callbackType
-
-var releaseCallbackFunction releaseCallbackType
-
-var releaseCallbackFunctionUserData interface {}
-
 //export licenseCallbackWrapper
 func licenseCallbackWrapper(status int) {
-	if licenseCallbackFuncion != nil {
-		licenseCallbackFuncion(status)
-	}
+	invokeLicenseCallback(status)
 }
 
 //export releaseUpdateCallbackWrapper
 func releaseUpdateCallbackWrapper(status int) {
-	if legacyReleaseCallbackFunction != nil {
-		legacyReleaseCallbackFunction(status)
-	}
+	invokeLegacyReleaseCallback(status)
 }
 
 //export newReleaseUpdateCallbackWrapper
-func newReleaseUpdateCallbackWrapper(status int, releaseJson *C.char) {
-   releaseJsonStr := ctoGoString(releaseJson)
-   if releaseCallbackFunction != nil {
-      if releaseJsonStr != "" {
-         release := &Release{}
-         json.Unmarshal([]byte(releaseJsonStr), release)
-         releaseCallbackFunction(status, release, releaseCallbackFunctionUserData)
-      } else {
-         releaseCallbackFunction(status, nil, releaseCallbackFunctionUserData)
-      }
-   }
+func newReleaseUpdateCallbackWrapper(status int, releaseJson *C.char, id C.uintptr_t) {
+	invokeReleaseCallback(uintptr(id), status, ctoGoString(releaseJson))
 }
 
 /*
@@ -258,8 +227,18 @@ func SetLicenseUserCredential(email string, password string) int {
    RETURN CODES: LA_OK, LA_E_PRODUCT_ID, LA_E_LICENSE_KEY
 */
 func SetLicenseCallback(callbackFunction func(int)) int {
+	previous := setLicenseCallback(callbackFunction)
+	status := C.SetLicenseCallback((C.CallbackType)(unsafe.Pointer(C.licenseCallbackCgoGateway)))
+	if int(status) != LA_OK {
+		setLicenseCallback(previous)
+	}
+	return int(status)
+}
+
+// registerLicenseCallback registers the license callback gateway without
+// replacing the callback set by SetLicenseCallback(), for LicenseEvents().
+func registerLicenseCallback() int {
 	status := C.SetLicenseCallback((C.CallbackType)(unsafe.Pointer(C.licenseCallbackCgoGateway)))
-	licenseCallbackFuncion = callbackFunction
 	return int(status)
 }
 
@@ -356,6 +335,7 @@ func SetAppVersion(appVersion string) int {
    PURPOSE: Sets the current release version of your application.
 
    The release version appears along with the activation details in dashboard.
+   It is validated with ParseVersion() before it is passed to the library.
 
    PARAMETERS:
    * releaseVersion - string in following allowed formats: x.x, x.x.x, x.x.x.x
@@ -363,6 +343,9 @@ func SetAppVersion(appVersion string) int {
    RETURN CODES: LA_OK, LA_E_PRODUCT_ID, LA_E_RELEASE_VERSION_FORMAT
 */
 func SetReleaseVersion(releaseVersion string) int {
+	if _, err := ParseVersion(releaseVersion); err != nil {
+		return LA_E_RELEASE_VERSION_FORMAT
+	}
 	cReleaseVersion := goToCString(releaseVersion)
 	status := C.SetReleaseVersion(cReleaseVersion)
 	freeCString(cReleaseVersion)
@@ -824,6 +807,9 @@ func GetLicenseOrganizationName(organizationName *string) int {
 
    PURPOSE: Gets the organization address associated with the license.
 
+   The JSON returned by the library is kept in organizationAddress.Raw. LA_FAIL
+   is returned if it cannot be decoded, see SetDecodeMode().
+
    PARAMETERS:
    * organizationAddress - pointer to the OrganizationAddress struct that receives the value
 
@@ -831,15 +817,23 @@ func GetLicenseOrganizationName(organizationName *string) int {
    LA_E_BUFFER_SIZE
 */
 func GetLicenseOrganizationAddress(organizationAddress *OrganizationAddress) int {
+   status, _ := getLicenseOrganizationAddress(organizationAddress)
+   return status
+}
+
+// getLicenseOrganizationAddress is GetLicenseOrganizationAddress() also
+// returning the error for the status, which is the *DecodeError of an
+// address that cannot be decoded.
+func getLicenseOrganizationAddress(organizationAddress *OrganizationAddress) (int, error) {
    var cOrganizationAddress = getCArray()
-   organizationAddressJson := ""
-   status := C.GetLicenseOrganizationAddressInternal(&cOrganizationAddress[0], maxCArrayLength)
-   organizationAddressJson = ctoGoString(&cOrganizationAddress[0])
-   if organizationAddressJson != "" {
-      address := []byte(organizationAddressJson)
-      json.Unmarshal(address, organizationAddress)
+   status := int(C.GetLicenseOrganizationAddressInternal(&cOrganizationAddress[0], maxCArrayLength))
+   organizationAddressJson := ctoGoString(&cOrganizationAddress[0])
+   if organizationAddressJson != "" && status == LA_OK {
+      if err := decodeOrganizationAddress(organizationAddressJson, organizationAddress); err != nil {
+         return LA_FAIL, err
+      }
    }
-   return int(status)
+   return status, StatusToError(status)
 }
 
 /*
@@ -1050,8 +1044,11 @@ func CheckForReleaseUpdate(platform string, version string, channel string, call
 	cPlatform := goToCString(platform)
 	cVersion := goToCString(version)
 	cChannel := goToCString(channel)
+	previous := setLegacyReleaseCallback(callbackFunction)
 	status := C.CheckForReleaseUpdate(cPlatform, cVersion, cChannel, (C.CallbackType)(unsafe.Pointer(C.releaseUpdateCallbackCgoGateway)))
-	legacyReleaseCallbackFunction = callbackFunction
+	if int(status) != LA_OK {
+		setLegacyReleaseCallback(previous)
+	}
 	freeCString(cPlatform)
 	freeCString(cVersion)
 	freeCString(cChannel)
@@ -1075,6 +1072,8 @@ func CheckForReleaseUpdate(platform string, version string, channel string, call
 
    * release- returns release struct of the latest available release, depending on the 
      flag LA_RELEASES_ALLOWED or LA_RELEASES_ALL passed to the CheckReleaseUpdate().
+     Its Raw field holds the JSON it was decoded from. Use CheckRelease() to have
+     decode errors reported.
 
    * userData - data that is passed to the callback function when it is registered
      using the CheckReleaseUpdate function. This parameter is optional and can be nil if no user data
@@ -1092,10 +1091,23 @@ func CheckForReleaseUpdate(platform string, version string, channel string, call
    LA_E_RELEASE_PLATFORM, LA_E_RELEASE_CHANNEL
 */
 func CheckReleaseUpdate(releaseUpdateCallbackFunction func(int, *Release, interface{}), releaseFlags uint, userData interface{}) int {
-   cReleaseFlags := (C.uint)(releaseFlags)
-	status := C.CheckReleaseUpdateInternal((C.ReleaseCallbackTypeInternal)(unsafe.Pointer(C.newReleaseUpdateCallbackCgoGateway)), cReleaseFlags, nil)
-	releaseCallbackFunction = releaseUpdateCallbackFunction
-   releaseCallbackFunctionUserData = userData
+	return checkReleaseUpdate(releaseFlags, func(status int, release *Release, err error) {
+		if releaseUpdateCallbackFunction != nil {
+			releaseUpdateCallbackFunction(status, release, userData)
+		}
+	})
+}
+
+// checkReleaseUpdate is CheckReleaseUpdate() with a callback of its own,
+// told apart from the ones of concurrent checks by the id passed as user
+// data.
+func checkReleaseUpdate(releaseFlags uint, callback releaseCheckCallback) int {
+	cReleaseFlags := (C.uint)(releaseFlags)
+	id := addReleaseCheck(callback)
+	status := C.CheckReleaseUpdateInternal((C.ReleaseCallbackTypeInternal)(unsafe.Pointer(C.newReleaseUpdateCallbackCgoGateway)), cReleaseFlags, C.releaseCheckUserData(C.uintptr_t(id)))
+	if int(status) != LA_OK {
+		removeReleaseCheck(id)
+	}
 	return int(status)
 }
 /*
@@ -0,0 +1,366 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package lexactivator
+
+// Backend is the full function set of LexActivator.h with the same
+// signatures as the package level functions. The native library is one
+// Backend, returned by Native(); tests can pass any other implementation,
+// such as the in-memory one in the fake package, to New() through
+// Config.Backend.
+type Backend interface {
+	SetProductFile(filePath string) int
+	SetProductData(productData string) int
+	SetProductId(productId string, flags uint) int
+	SetDataDirectory(directoryPath string) int
+	SetCustomDeviceFingerprint(fingerprint string) int
+	SetLicenseKey(licenseKey string) int
+	SetLicenseUserCredential(email string, password string) int
+	SetLicenseCallback(callbackFunction func(int)) int
+	SetActivationLeaseDuration(leaseDuration uint) int
+	SetActivationMetadata(key string, value string) int
+	SetTrialActivationMetadata(key string, value string) int
+	SetAppVersion(appVersion string) int
+	SetReleaseVersion(releaseVersion string) int
+	SetReleasePublishedDate(releasePublishedDate uint) int
+	SetReleasePlatform(releasePlatform string) int
+	SetReleaseChannel(releaseChannel string) int
+	SetOfflineActivationRequestMeterAttributeUses(name string, uses uint) int
+	SetNetworkProxy(proxy string) int
+	SetCryptlexHost(host string) int
+	GetProductMetadata(key string, value *string) int
+	GetProductVersionName(name *string) int
+	GetProductVersionDisplayName(displayName *string) int
+	GetProductVersionFeatureFlag(name string, enabled *bool, data *string) int
+	GetLicenseMetadata(key string, value *string) int
+	GetLicenseMeterAttribute(name string, allowedUses *uint, totalUses *uint, grossUses *uint) int
+	GetLicenseKey(licenseKey *string) int
+	GetLicenseAllowedActivations(allowedActivations *uint) int
+	GetLicenseTotalActivations(totalActivations *uint) int
+	GetLicenseExpiryDate(expiryDate *uint) int
+	GetLicenseMaintenanceExpiryDate(maintenanceExpiryDate *uint) int
+	GetLicenseMaxAllowedReleaseVersion(maxAllowedReleaseVersion *string) int
+	GetLicenseUserEmail(email *string) int
+	GetLicenseUserName(name *string) int
+	GetLicenseUserCompany(company *string) int
+	GetLicenseUserMetadata(key string, value *string) int
+	GetLicenseOrganizationName(organizationName *string) int
+	GetLicenseOrganizationAddress(organizationAddress *OrganizationAddress) int
+	GetLicenseType(licenseType *string) int
+	GetActivationMetadata(key string, value *string) int
+	GetActivationMode(initialMode *string, currentMode *string) int
+	GetActivationMeterAttributeUses(name string, uses *uint) int
+	GetServerSyncGracePeriodExpiryDate(expiryDate *uint) int
+	GetTrialActivationMetadata(key string, value *string) int
+	GetTrialExpiryDate(trialExpiryDate *uint) int
+	GetTrialId(trialId *string) int
+	GetLocalTrialExpiryDate(trialExpiryDate *uint) int
+	GetLibraryVersion(libraryVersion *string) int
+	CheckForReleaseUpdate(platform string, version string, channel string, callbackFunction func(int)) int
+	CheckReleaseUpdate(releaseUpdateCallbackFunction func(int, *Release, interface{}), releaseFlags uint, userData interface{}) int
+	ActivateLicense() int
+	ActivateLicenseOffline(filePath string) int
+	GenerateOfflineActivationRequest(filePath string) int
+	DeactivateLicense() int
+	GenerateOfflineDeactivationRequest(filePath string) int
+	IsLicenseGenuine() int
+	IsLicenseValid() int
+	ActivateTrial() int
+	ActivateTrialOffline(filePath string) int
+	GenerateOfflineTrialActivationRequest(filePath string) int
+	IsTrialGenuine() int
+	ActivateLocalTrial(trialLength uint) int
+	IsLocalTrialGenuine() int
+	ExtendLocalTrial(trialExtensionLength uint) int
+	IncrementActivationMeterAttributeUses(name string, increment uint) int
+	DecrementActivationMeterAttributeUses(name string, decrement uint) int
+	ResetActivationMeterAttributeUses(name string) int
+	Reset() int
+}
+
+type nativeBackend struct{}
+
+var (
+	_ releaseChecker            = nativeBackend{}
+	_ organizationAddressGetter = nativeBackend{}
+)
+
+// Native returns the Backend calling into the LexActivator library.
+func Native() Backend {
+	return nativeBackend{}
+}
+
+func (nativeBackend) SetProductFile(filePath string) int {
+	return SetProductFile(filePath)
+}
+
+func (nativeBackend) SetProductData(productData string) int {
+	return SetProductData(productData)
+}
+
+func (nativeBackend) SetProductId(productId string, flags uint) int {
+	return SetProductId(productId, flags)
+}
+
+func (nativeBackend) SetDataDirectory(directoryPath string) int {
+	return SetDataDirectory(directoryPath)
+}
+
+func (nativeBackend) SetCustomDeviceFingerprint(fingerprint string) int {
+	return SetCustomDeviceFingerprint(fingerprint)
+}
+
+func (nativeBackend) SetLicenseKey(licenseKey string) int {
+	return SetLicenseKey(licenseKey)
+}
+
+func (nativeBackend) SetLicenseUserCredential(email string, password string) int {
+	return SetLicenseUserCredential(email, password)
+}
+
+func (nativeBackend) SetLicenseCallback(callbackFunction func(int)) int {
+	return SetLicenseCallback(callbackFunction)
+}
+
+func (nativeBackend) SetActivationLeaseDuration(leaseDuration uint) int {
+	return SetActivationLeaseDuration(leaseDuration)
+}
+
+func (nativeBackend) SetActivationMetadata(key string, value string) int {
+	return SetActivationMetadata(key, value)
+}
+
+func (nativeBackend) SetTrialActivationMetadata(key string, value string) int {
+	return SetTrialActivationMetadata(key, value)
+}
+
+func (nativeBackend) SetAppVersion(appVersion string) int {
+	return SetAppVersion(appVersion)
+}
+
+func (nativeBackend) SetReleaseVersion(releaseVersion string) int {
+	return SetReleaseVersion(releaseVersion)
+}
+
+func (nativeBackend) SetReleasePublishedDate(releasePublishedDate uint) int {
+	return SetReleasePublishedDate(releasePublishedDate)
+}
+
+func (nativeBackend) SetReleasePlatform(releasePlatform string) int {
+	return SetReleasePlatform(releasePlatform)
+}
+
+func (nativeBackend) SetReleaseChannel(releaseChannel string) int {
+	return SetReleaseChannel(releaseChannel)
+}
+
+func (nativeBackend) SetOfflineActivationRequestMeterAttributeUses(name string, uses uint) int {
+	return SetOfflineActivationRequestMeterAttributeUses(name, uses)
+}
+
+func (nativeBackend) SetNetworkProxy(proxy string) int {
+	return SetNetworkProxy(proxy)
+}
+
+func (nativeBackend) SetCryptlexHost(host string) int {
+	return SetCryptlexHost(host)
+}
+
+func (nativeBackend) GetProductMetadata(key string, value *string) int {
+	return GetProductMetadata(key, value)
+}
+
+func (nativeBackend) GetProductVersionName(name *string) int {
+	return GetProductVersionName(name)
+}
+
+func (nativeBackend) GetProductVersionDisplayName(displayName *string) int {
+	return GetProductVersionDisplayName(displayName)
+}
+
+func (nativeBackend) GetProductVersionFeatureFlag(name string, enabled *bool, data *string) int {
+	return GetProductVersionFeatureFlag(name, enabled, data)
+}
+
+func (nativeBackend) GetLicenseMetadata(key string, value *string) int {
+	return GetLicenseMetadata(key, value)
+}
+
+func (nativeBackend) GetLicenseMeterAttribute(name string, allowedUses *uint, totalUses *uint, grossUses *uint) int {
+	return GetLicenseMeterAttribute(name, allowedUses, totalUses, grossUses)
+}
+
+func (nativeBackend) GetLicenseKey(licenseKey *string) int {
+	return GetLicenseKey(licenseKey)
+}
+
+func (nativeBackend) GetLicenseAllowedActivations(allowedActivations *uint) int {
+	return GetLicenseAllowedActivations(allowedActivations)
+}
+
+func (nativeBackend) GetLicenseTotalActivations(totalActivations *uint) int {
+	return GetLicenseTotalActivations(totalActivations)
+}
+
+func (nativeBackend) GetLicenseExpiryDate(expiryDate *uint) int {
+	return GetLicenseExpiryDate(expiryDate)
+}
+
+func (nativeBackend) GetLicenseMaintenanceExpiryDate(maintenanceExpiryDate *uint) int {
+	return GetLicenseMaintenanceExpiryDate(maintenanceExpiryDate)
+}
+
+func (nativeBackend) GetLicenseMaxAllowedReleaseVersion(maxAllowedReleaseVersion *string) int {
+	return GetLicenseMaxAllowedReleaseVersion(maxAllowedReleaseVersion)
+}
+
+func (nativeBackend) GetLicenseUserEmail(email *string) int {
+	return GetLicenseUserEmail(email)
+}
+
+func (nativeBackend) GetLicenseUserName(name *string) int {
+	return GetLicenseUserName(name)
+}
+
+func (nativeBackend) GetLicenseUserCompany(company *string) int {
+	return GetLicenseUserCompany(company)
+}
+
+func (nativeBackend) GetLicenseUserMetadata(key string, value *string) int {
+	return GetLicenseUserMetadata(key, value)
+}
+
+func (nativeBackend) GetLicenseOrganizationName(organizationName *string) int {
+	return GetLicenseOrganizationName(organizationName)
+}
+
+func (nativeBackend) GetLicenseOrganizationAddress(organizationAddress *OrganizationAddress) int {
+	return GetLicenseOrganizationAddress(organizationAddress)
+}
+
+func (nativeBackend) getLicenseOrganizationAddress(organizationAddress *OrganizationAddress) (int, error) {
+	return getLicenseOrganizationAddress(organizationAddress)
+}
+
+func (nativeBackend) GetLicenseType(licenseType *string) int {
+	return GetLicenseType(licenseType)
+}
+
+func (nativeBackend) GetActivationMetadata(key string, value *string) int {
+	return GetActivationMetadata(key, value)
+}
+
+func (nativeBackend) GetActivationMode(initialMode *string, currentMode *string) int {
+	return GetActivationMode(initialMode, currentMode)
+}
+
+func (nativeBackend) GetActivationMeterAttributeUses(name string, uses *uint) int {
+	return GetActivationMeterAttributeUses(name, uses)
+}
+
+func (nativeBackend) GetServerSyncGracePeriodExpiryDate(expiryDate *uint) int {
+	return GetServerSyncGracePeriodExpiryDate(expiryDate)
+}
+
+func (nativeBackend) GetTrialActivationMetadata(key string, value *string) int {
+	return GetTrialActivationMetadata(key, value)
+}
+
+func (nativeBackend) GetTrialExpiryDate(trialExpiryDate *uint) int {
+	return GetTrialExpiryDate(trialExpiryDate)
+}
+
+func (nativeBackend) GetTrialId(trialId *string) int {
+	return GetTrialId(trialId)
+}
+
+func (nativeBackend) GetLocalTrialExpiryDate(trialExpiryDate *uint) int {
+	return GetLocalTrialExpiryDate(trialExpiryDate)
+}
+
+func (nativeBackend) GetLibraryVersion(libraryVersion *string) int {
+	return GetLibraryVersion(libraryVersion)
+}
+
+func (nativeBackend) CheckForReleaseUpdate(platform string, version string, channel string, callbackFunction func(int)) int {
+	return CheckForReleaseUpdate(platform, version, channel, callbackFunction)
+}
+
+func (nativeBackend) CheckReleaseUpdate(releaseUpdateCallbackFunction func(int, *Release, interface{}), releaseFlags uint, userData interface{}) int {
+	return CheckReleaseUpdate(releaseUpdateCallbackFunction, releaseFlags, userData)
+}
+
+func (nativeBackend) ActivateLicense() int {
+	return ActivateLicense()
+}
+
+func (nativeBackend) ActivateLicenseOffline(filePath string) int {
+	return ActivateLicenseOffline(filePath)
+}
+
+func (nativeBackend) GenerateOfflineActivationRequest(filePath string) int {
+	return GenerateOfflineActivationRequest(filePath)
+}
+
+func (nativeBackend) DeactivateLicense() int {
+	return DeactivateLicense()
+}
+
+func (nativeBackend) GenerateOfflineDeactivationRequest(filePath string) int {
+	return GenerateOfflineDeactivationRequest(filePath)
+}
+
+func (nativeBackend) IsLicenseGenuine() int {
+	return IsLicenseGenuine()
+}
+
+func (nativeBackend) IsLicenseValid() int {
+	return IsLicenseValid()
+}
+
+func (nativeBackend) ActivateTrial() int {
+	return ActivateTrial()
+}
+
+func (nativeBackend) ActivateTrialOffline(filePath string) int {
+	return ActivateTrialOffline(filePath)
+}
+
+func (nativeBackend) GenerateOfflineTrialActivationRequest(filePath string) int {
+	return GenerateOfflineTrialActivationRequest(filePath)
+}
+
+func (nativeBackend) IsTrialGenuine() int {
+	return IsTrialGenuine()
+}
+
+func (nativeBackend) ActivateLocalTrial(trialLength uint) int {
+	return ActivateLocalTrial(trialLength)
+}
+
+func (nativeBackend) IsLocalTrialGenuine() int {
+	return IsLocalTrialGenuine()
+}
+
+func (nativeBackend) ExtendLocalTrial(trialExtensionLength uint) int {
+	return ExtendLocalTrial(trialExtensionLength)
+}
+
+func (nativeBackend) IncrementActivationMeterAttributeUses(name string, increment uint) int {
+	return IncrementActivationMeterAttributeUses(name, increment)
+}
+
+func (nativeBackend) DecrementActivationMeterAttributeUses(name string, decrement uint) int {
+	return DecrementActivationMeterAttributeUses(name, decrement)
+}
+
+func (nativeBackend) ResetActivationMeterAttributeUses(name string) int {
+	return ResetActivationMeterAttributeUses(name)
+}
+
+func (nativeBackend) Reset() int {
+	return Reset()
+}
+
+func (nativeBackend) checkReleaseUpdate(releaseFlags uint, callback releaseCheckCallback) int {
+	return checkReleaseUpdate(releaseFlags, callback)
+}
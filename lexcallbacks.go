@@ -0,0 +1,151 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package lexactivator
+
+import (
+	"log"
+	"runtime/debug"
+	"sync"
+)
+
+// The native library invokes callbacks from its own threads, e.g. the
+// server sync thread, while the Go callbacks are registered from any
+// goroutine. The registry below guards them with a mutex, and every Go
+// callback runs under recoverCallback() so that a panicking callback
+// cannot take down the host process: a panic unwinding into the native
+// library's thread would abort it.
+
+type callbackType func(int)
+
+// CallbackPanicHandler is called with the name of the callback and the
+// recovered value when a callback passed to SetLicenseCallback(),
+// CheckForReleaseUpdate() or CheckReleaseUpdate() panics.
+type CallbackPanicHandler func(callback string, recovered interface{})
+
+var callbacks struct {
+	sync.Mutex
+	license          callbackType
+	legacyRelease    callbackType
+	releaseChecks    map[uintptr]releaseCheckCallback
+	nextReleaseCheck uintptr
+	panicHandler     CallbackPanicHandler
+}
+
+// SetCallbackPanicHandler sets the handler called when a callback panics.
+// The default handler logs the panic and its stack trace with the log
+// package. Passing nil restores the default handler.
+func SetCallbackPanicHandler(handler CallbackPanicHandler) {
+	callbacks.Lock()
+	defer callbacks.Unlock()
+	callbacks.panicHandler = handler
+}
+
+func logCallbackPanic(callback string, recovered interface{}) {
+	log.Printf("lexactivator: %s callback panicked: %v\n%s", callback, recovered, debug.Stack())
+}
+
+// recoverCallback must be deferred by every function invoking a Go callback.
+func recoverCallback(callback string) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	callbacks.Lock()
+	handler := callbacks.panicHandler
+	callbacks.Unlock()
+	if handler == nil {
+		handler = logCallbackPanic
+	}
+	defer func() {
+		// The handler itself must not crash the process either.
+		if recovered := recover(); recovered != nil {
+			logCallbackPanic(callback+" panic handler", recovered)
+		}
+	}()
+	handler(callback, recovered)
+}
+
+// setLicenseCallback installs the license callback and returns the one it
+// replaced.
+func setLicenseCallback(callback callbackType) callbackType {
+	callbacks.Lock()
+	defer callbacks.Unlock()
+	previous := callbacks.license
+	callbacks.license = callback
+	return previous
+}
+
+func invokeLicenseCallback(status int) {
+	nativeEvents.publish(status)
+	defer recoverCallback("license")
+	callbacks.Lock()
+	callback := callbacks.license
+	callbacks.Unlock()
+	if callback != nil {
+		callback(status)
+	}
+}
+
+// setLegacyReleaseCallback installs the CheckForReleaseUpdate() callback
+// and returns the one it replaced.
+func setLegacyReleaseCallback(callback callbackType) callbackType {
+	callbacks.Lock()
+	defer callbacks.Unlock()
+	previous := callbacks.legacyRelease
+	callbacks.legacyRelease = callback
+	return previous
+}
+
+func invokeLegacyReleaseCallback(status int) {
+	defer recoverCallback("release update")
+	callbacks.Lock()
+	callback := callbacks.legacyRelease
+	callbacks.Unlock()
+	if callback != nil {
+		callback(status)
+	}
+}
+
+// releaseCheckCallback receives the result of a CheckReleaseUpdate() call
+// along with the error decoding the release.
+type releaseCheckCallback func(status int, release *Release, err error)
+
+// addReleaseCheck registers the callback of a release update check and
+// returns the id the native library passes back as user data, so that
+// concurrent checks each get their own result.
+func addReleaseCheck(callback releaseCheckCallback) uintptr {
+	callbacks.Lock()
+	defer callbacks.Unlock()
+	if callbacks.releaseChecks == nil {
+		callbacks.releaseChecks = map[uintptr]releaseCheckCallback{}
+	}
+	callbacks.nextReleaseCheck++
+	id := callbacks.nextReleaseCheck
+	callbacks.releaseChecks[id] = callback
+	return id
+}
+
+func removeReleaseCheck(id uintptr) {
+	callbacks.Lock()
+	defer callbacks.Unlock()
+	delete(callbacks.releaseChecks, id)
+}
+
+// invokeReleaseCallback invokes and unregisters the callback of the check
+// with the given id. The result of an unknown id is logged and dropped, as
+// it cannot be told which check it answers.
+func invokeReleaseCallback(id uintptr, status int, releaseJson string) {
+	defer recoverCallback("release update")
+	callbacks.Lock()
+	callback, ok := callbacks.releaseChecks[id]
+	delete(callbacks.releaseChecks, id)
+	callbacks.Unlock()
+	if !ok {
+		log.Printf("lexactivator: dropping the %s result of unknown release update check %d", Status(status), id)
+		return
+	}
+	if callback != nil {
+		release, err := decodeRelease(releaseJson)
+		callback(status, release, err)
+	}
+}
@@ -0,0 +1,22 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package lexactivator
+
+import "testing"
+
+func TestInvokeReleaseCallbackUnknownId(t *testing.T) {
+	var statuses []int
+	id := addReleaseCheck(func(status int, release *Release, err error) {
+		statuses = append(statuses, status)
+	})
+	defer removeReleaseCheck(id)
+
+	invokeReleaseCallback(id+1, LA_E_INET, "")
+	if len(statuses) != 0 {
+		t.Fatalf("the result of an unknown check went to check %d", id)
+	}
+	invokeReleaseCallback(id, LA_RELEASE_UPDATE_NOT_AVAILABLE, "")
+	if len(statuses) != 1 || statuses[0] != LA_RELEASE_UPDATE_NOT_AVAILABLE {
+		t.Fatalf("check %d got %v", id, statuses)
+	}
+}
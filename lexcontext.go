@@ -0,0 +1,237 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package lexactivator
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// The functions in this file are context aware variants of the functions
+// that block on network I/O inside the native library. The native call
+// cannot be interrupted, so it runs on a worker goroutine and the variant
+// returns ctx.Err() as soon as ctx is done. The abandoned call keeps
+// running to completion and its result is handed to the late result
+// handler, as the license state may well have changed, e.g. an activation
+// that completed after its caller gave up on it.
+
+// LateResult is the result of a native call whose context was done before
+// the call returned.
+type LateResult struct {
+	// Call is the name of the function, e.g. "ActivateLicense".
+	Call string
+
+	// Status is the status the native call eventually returned.
+	Status Status
+
+	// Err is the context error the caller was returned.
+	Err error
+
+	// Started and Finished are the times the native call was issued and
+	// returned.
+	Started  time.Time
+	Finished time.Time
+}
+
+// LateResultHandler is called with the result of every abandoned native
+// call.
+type LateResultHandler func(LateResult)
+
+var lateResults struct {
+	sync.Mutex
+	handler LateResultHandler
+	pending sync.WaitGroup
+}
+
+// SetLateResultHandler sets the handler called with the results of native
+// calls abandoned by the ...Context() functions. The default handler logs
+// them with the log package. Passing nil restores the default handler.
+func SetLateResultHandler(handler LateResultHandler) {
+	lateResults.Lock()
+	defer lateResults.Unlock()
+	lateResults.handler = handler
+}
+
+// WaitLateResults waits until every abandoned native call has returned and
+// its result has been handled, e.g. before exiting the program.
+func WaitLateResults() {
+	lateResults.pending.Wait()
+}
+
+func logLateResult(result LateResult) {
+	log.Printf("lexactivator: %s returned %v after %v, its caller gave up: %v",
+		result.Call, result.Status, result.Finished.Sub(result.Started), result.Err)
+}
+
+func handleLateResult(result LateResult) {
+	defer recoverCallback("late result")
+	lateResults.Lock()
+	handler := lateResults.handler
+	lateResults.Unlock()
+	if handler == nil {
+		handler = logLateResult
+	}
+	handler(result)
+}
+
+// callContext runs call on a worker goroutine and waits for its status
+// until ctx is done.
+func callContext(ctx context.Context, name string, call func() int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	var (
+		mu        sync.Mutex
+		finished  bool
+		result    int
+		abandoned error
+	)
+	started := time.Now()
+	done := make(chan int, 1)
+	lateResults.pending.Add(1)
+	go func() {
+		defer lateResults.pending.Done()
+		status := call()
+		mu.Lock()
+		finished, result = true, status
+		err := abandoned
+		mu.Unlock()
+		if err == nil {
+			done <- status
+			return
+		}
+		handleLateResult(LateResult{
+			Call:     name,
+			Status:   Status(status),
+			Err:      err,
+			Started:  started,
+			Finished: time.Now(),
+		})
+	}()
+	select {
+	case status := <-done:
+		return StatusToError(status)
+	case <-ctx.Done():
+		mu.Lock()
+		defer mu.Unlock()
+		// The call may have returned in the meantime, in which case its
+		// result is not late after all.
+		if finished {
+			return StatusToError(result)
+		}
+		abandoned = ctx.Err()
+		return abandoned
+	}
+}
+
+// ActivateLicenseContext is ActivateLicense() returning ctx.Err() once ctx
+// is done.
+func ActivateLicenseContext(ctx context.Context) error {
+	return callContext(ctx, "ActivateLicense", ActivateLicense)
+}
+
+// DeactivateLicenseContext is DeactivateLicense() returning ctx.Err() once
+// ctx is done.
+func DeactivateLicenseContext(ctx context.Context) error {
+	return callContext(ctx, "DeactivateLicense", DeactivateLicense)
+}
+
+// IsLicenseGenuineContext is IsLicenseGenuine() returning ctx.Err() once
+// ctx is done.
+func IsLicenseGenuineContext(ctx context.Context) error {
+	return callContext(ctx, "IsLicenseGenuine", IsLicenseGenuine)
+}
+
+// ActivateTrialContext is ActivateTrial() returning ctx.Err() once ctx is
+// done.
+func ActivateTrialContext(ctx context.Context) error {
+	return callContext(ctx, "ActivateTrial", ActivateTrial)
+}
+
+// IncrementActivationMeterAttributeUsesContext is
+// IncrementActivationMeterAttributeUses() returning ctx.Err() once ctx is
+// done.
+func IncrementActivationMeterAttributeUsesContext(ctx context.Context, name string, increment uint) error {
+	return callContext(ctx, "IncrementActivationMeterAttributeUses", func() int {
+		return IncrementActivationMeterAttributeUses(name, increment)
+	})
+}
+
+// DecrementActivationMeterAttributeUsesContext is
+// DecrementActivationMeterAttributeUses() returning ctx.Err() once ctx is
+// done.
+func DecrementActivationMeterAttributeUsesContext(ctx context.Context, name string, decrement uint) error {
+	return callContext(ctx, "DecrementActivationMeterAttributeUses", func() int {
+		return DecrementActivationMeterAttributeUses(name, decrement)
+	})
+}
+
+// ResetActivationMeterAttributeUsesContext is
+// ResetActivationMeterAttributeUses() returning ctx.Err() once ctx is done.
+func ResetActivationMeterAttributeUsesContext(ctx context.Context, name string) error {
+	return callContext(ctx, "ResetActivationMeterAttributeUses", func() int {
+		return ResetActivationMeterAttributeUses(name)
+	})
+}
+
+// ActivateLicenseContext is ActivateLicense() returning ctx.Err() once ctx
+// is done.
+func (c *Client) ActivateLicenseContext(ctx context.Context) error {
+	return c.retry(ctx, "ActivateLicense", func() error {
+		return callContext(ctx, "ActivateLicense", c.backend.ActivateLicense)
+	})
+}
+
+// DeactivateLicenseContext is DeactivateLicense() returning ctx.Err() once
+// ctx is done.
+func (c *Client) DeactivateLicenseContext(ctx context.Context) error {
+	return callContext(ctx, "DeactivateLicense", c.backend.DeactivateLicense)
+}
+
+// IsLicenseGenuineContext is IsLicenseGenuine() returning ctx.Err() once
+// ctx is done.
+func (c *Client) IsLicenseGenuineContext(ctx context.Context) error {
+	return callContext(ctx, "IsLicenseGenuine", c.backend.IsLicenseGenuine)
+}
+
+// ActivateTrialContext is ActivateTrial() returning ctx.Err() once ctx is
+// done.
+func (c *Client) ActivateTrialContext(ctx context.Context) error {
+	return c.retry(ctx, "ActivateTrial", func() error {
+		return callContext(ctx, "ActivateTrial", c.backend.ActivateTrial)
+	})
+}
+
+// IncrementActivationMeterAttributeUsesContext is
+// IncrementActivationMeterAttributeUses() returning ctx.Err() once ctx is
+// done.
+func (c *Client) IncrementActivationMeterAttributeUsesContext(ctx context.Context, name string, increment uint) error {
+	return c.retry(ctx, "IncrementActivationMeterAttributeUses", func() error {
+		return callContext(ctx, "IncrementActivationMeterAttributeUses", func() int {
+			return c.backend.IncrementActivationMeterAttributeUses(name, increment)
+		})
+	})
+}
+
+// DecrementActivationMeterAttributeUsesContext is
+// DecrementActivationMeterAttributeUses() returning ctx.Err() once ctx is
+// done.
+func (c *Client) DecrementActivationMeterAttributeUsesContext(ctx context.Context, name string, decrement uint) error {
+	return c.retry(ctx, "DecrementActivationMeterAttributeUses", func() error {
+		return callContext(ctx, "DecrementActivationMeterAttributeUses", func() int {
+			return c.backend.DecrementActivationMeterAttributeUses(name, decrement)
+		})
+	})
+}
+
+// ResetActivationMeterAttributeUsesContext is
+// ResetActivationMeterAttributeUses() returning ctx.Err() once ctx is done.
+func (c *Client) ResetActivationMeterAttributeUsesContext(ctx context.Context, name string) error {
+	return c.retry(ctx, "ResetActivationMeterAttributeUses", func() error {
+		return callContext(ctx, "ResetActivationMeterAttributeUses", func() int {
+			return c.backend.ResetActivationMeterAttributeUses(name)
+		})
+	})
+}
@@ -0,0 +1,215 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package lexactivator
+
+import (
+	"errors"
+	"fmt"
+)
+
+// StatusError is the error form of a LexActivator status code. Every
+// LA_* code other than LA_OK maps to a StatusError carrying the code,
+// its symbolic name and the human readable message from LexStatusCodes.h.
+type StatusError struct {
+	Code    int
+	Name    string
+	Message string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("lexactivator: %s (%d): %s", e.Name, e.Code, e.Message)
+}
+
+// Is reports whether target is a *StatusError with the same code, which
+// lets the Err* sentinels below be matched with errors.Is.
+func (e *StatusError) Is(target error) bool {
+	t, ok := target.(*StatusError)
+	return ok && t.Code == e.Code
+}
+
+// Informational reports whether the status describes the state of an
+// otherwise genuine license, trial or release (LA_EXPIRED, LA_SUSPENDED,
+// LA_RELEASE_UPDATE_AVAILABLE, ...) rather than a failure of the call.
+func (e *StatusError) Informational() bool {
+	return isInformationalStatus(e.Code)
+}
+
+type statusDetail struct {
+	name    string
+	message string
+}
+
+var statusDetails = map[int]statusDetail{
+	LA_OK:                                   {"LA_OK", "Success code."},
+	LA_FAIL:                                 {"LA_FAIL", "Failure code."},
+	LA_EXPIRED:                              {"LA_EXPIRED", "The license has expired or system time has been tampered with. Ensure your date and time settings are correct."},
+	LA_SUSPENDED:                            {"LA_SUSPENDED", "The license has been suspended."},
+	LA_GRACE_PERIOD_OVER:                    {"LA_GRACE_PERIOD_OVER", "The grace period for server sync is over."},
+	LA_TRIAL_EXPIRED:                        {"LA_TRIAL_EXPIRED", "The trial has expired or system time has been tampered with. Ensure your date and time settings are correct."},
+	LA_LOCAL_TRIAL_EXPIRED:                  {"LA_LOCAL_TRIAL_EXPIRED", "The local trial has expired or system time has been tampered with. Ensure your date and time settings are correct."},
+	LA_RELEASE_UPDATE_AVAILABLE:             {"LA_RELEASE_UPDATE_AVAILABLE", "A new update is available for the product. This means a new release has been published for the product."},
+	LA_RELEASE_UPDATE_NOT_AVAILABLE:         {"LA_RELEASE_UPDATE_NOT_AVAILABLE", "No new update is available for the product. The current version is latest."},
+	LA_RELEASE_UPDATE_AVAILABLE_NOT_ALLOWED: {"LA_RELEASE_UPDATE_AVAILABLE_NOT_ALLOWED", "The update available is not allowed for this license."},
+	LA_E_FILE_PATH:                          {"LA_E_FILE_PATH", "Invalid file path."},
+	LA_E_PRODUCT_FILE:                       {"LA_E_PRODUCT_FILE", "Invalid or corrupted product file."},
+	LA_E_PRODUCT_DATA:                       {"LA_E_PRODUCT_DATA", "Invalid product data."},
+	LA_E_PRODUCT_ID:                         {"LA_E_PRODUCT_ID", "The product id is incorrect."},
+	LA_E_SYSTEM_PERMISSION:                  {"LA_E_SYSTEM_PERMISSION", "Insufficient system permissions. Occurs when LA_SYSTEM flag is used but application is not run with admin privileges."},
+	LA_E_FILE_PERMISSION:                    {"LA_E_FILE_PERMISSION", "No permission to write to file."},
+	LA_E_WMIC:                               {"LA_E_WMIC", "Fingerprint couldn't be generated because Windows Management Instrumentation (WMI) service has been disabled. This error is specific to Windows only."},
+	LA_E_TIME:                               {"LA_E_TIME", "The difference between the network time and the system time is more than allowed clock offset."},
+	LA_E_INET:                               {"LA_E_INET", "Failed to connect to the server due to network error."},
+	LA_E_NET_PROXY:                          {"LA_E_NET_PROXY", "Invalid network proxy."},
+	LA_E_HOST_URL:                           {"LA_E_HOST_URL", "Invalid Cryptlex host url."},
+	LA_E_BUFFER_SIZE:                        {"LA_E_BUFFER_SIZE", "The buffer size was smaller than required."},
+	LA_E_APP_VERSION_LENGTH:                 {"LA_E_APP_VERSION_LENGTH", "App version length is more than 256 characters."},
+	LA_E_REVOKED:                            {"LA_E_REVOKED", "The license has been revoked."},
+	LA_E_LICENSE_KEY:                        {"LA_E_LICENSE_KEY", "Invalid license key."},
+	LA_E_LICENSE_TYPE:                       {"LA_E_LICENSE_TYPE", "Invalid license type. Make sure floating license is not being used."},
+	LA_E_OFFLINE_RESPONSE_FILE:              {"LA_E_OFFLINE_RESPONSE_FILE", "Invalid offline activation response file."},
+	LA_E_OFFLINE_RESPONSE_FILE_EXPIRED:      {"LA_E_OFFLINE_RESPONSE_FILE_EXPIRED", "The offline activation response has expired."},
+	LA_E_ACTIVATION_LIMIT:                   {"LA_E_ACTIVATION_LIMIT", "The license has reached it's allowed activations limit."},
+	LA_E_ACTIVATION_NOT_FOUND:               {"LA_E_ACTIVATION_NOT_FOUND", "The license activation was deleted on the server."},
+	LA_E_DEACTIVATION_LIMIT:                 {"LA_E_DEACTIVATION_LIMIT", "The license has reached it's allowed deactivations limit."},
+	LA_E_TRIAL_NOT_ALLOWED:                  {"LA_E_TRIAL_NOT_ALLOWED", "Trial not allowed for the product."},
+	LA_E_TRIAL_ACTIVATION_LIMIT:             {"LA_E_TRIAL_ACTIVATION_LIMIT", "Your account has reached it's trial activations limit."},
+	LA_E_MACHINE_FINGERPRINT:                {"LA_E_MACHINE_FINGERPRINT", "Machine fingerprint has changed since activation."},
+	LA_E_METADATA_KEY_LENGTH:                {"LA_E_METADATA_KEY_LENGTH", "Metadata key length is more than 256 characters."},
+	LA_E_METADATA_VALUE_LENGTH:              {"LA_E_METADATA_VALUE_LENGTH", "Metadata value length is more than 256 characters."},
+	LA_E_ACTIVATION_METADATA_LIMIT:          {"LA_E_ACTIVATION_METADATA_LIMIT", "The license has reached it's metadata fields limit."},
+	LA_E_TRIAL_ACTIVATION_METADATA_LIMIT:    {"LA_E_TRIAL_ACTIVATION_METADATA_LIMIT", "The trial has reached it's metadata fields limit."},
+	LA_E_METADATA_KEY_NOT_FOUND:             {"LA_E_METADATA_KEY_NOT_FOUND", "The metadata key does not exist."},
+	LA_E_TIME_MODIFIED:                      {"LA_E_TIME_MODIFIED", "The system time has been tampered (backdated)."},
+	LA_E_RELEASE_VERSION_FORMAT:             {"LA_E_RELEASE_VERSION_FORMAT", "Invalid version format."},
+	LA_E_AUTHENTICATION_FAILED:              {"LA_E_AUTHENTICATION_FAILED", "Incorrect email or password."},
+	LA_E_METER_ATTRIBUTE_NOT_FOUND:          {"LA_E_METER_ATTRIBUTE_NOT_FOUND", "The meter attribute does not exist."},
+	LA_E_METER_ATTRIBUTE_USES_LIMIT_REACHED: {"LA_E_METER_ATTRIBUTE_USES_LIMIT_REACHED", "The meter attribute has reached it's usage limit."},
+	LA_E_CUSTOM_FINGERPRINT_LENGTH:          {"LA_E_CUSTOM_FINGERPRINT_LENGTH", "Custom device fingerprint length is less than 64 characters or more than 256 characters."},
+	LA_E_PRODUCT_VERSION_NOT_LINKED:         {"LA_E_PRODUCT_VERSION_NOT_LINKED", "No product version is linked with the license."},
+	LA_E_FEATURE_FLAG_NOT_FOUND:             {"LA_E_FEATURE_FLAG_NOT_FOUND", "The product version feature flag does not exist."},
+	LA_E_RELEASE_VERSION_NOT_ALLOWED:        {"LA_E_RELEASE_VERSION_NOT_ALLOWED", "The release version is not allowed."},
+	LA_E_RELEASE_PLATFORM_LENGTH:            {"LA_E_RELEASE_PLATFORM_LENGTH", "Release platform length is more than 256 characters."},
+	LA_E_RELEASE_CHANNEL_LENGTH:             {"LA_E_RELEASE_CHANNEL_LENGTH", "Release channel length is more than 256 characters."},
+	LA_E_VM:                                 {"LA_E_VM", "Application is being run inside a virtual machine / hypervisor, and activation has been disallowed in the VM."},
+	LA_E_COUNTRY:                            {"LA_E_COUNTRY", "Country is not allowed."},
+	LA_E_IP:                                 {"LA_E_IP", "IP address is not allowed."},
+	LA_E_CONTAINER:                          {"LA_E_CONTAINER", "Application is being run inside a container and activation has been disallowed in the container."},
+	LA_E_RELEASE_VERSION:                    {"LA_E_RELEASE_VERSION", "Invalid release version. Make sure the release version uses the following formats: x.x, x.x.x, x.x.x.x (where x is a number)."},
+	LA_E_RELEASE_PLATFORM:                   {"LA_E_RELEASE_PLATFORM", "Release platform not set."},
+	LA_E_RELEASE_CHANNEL:                    {"LA_E_RELEASE_CHANNEL", "Release channel not set."},
+	LA_E_RATE_LIMIT:                         {"LA_E_RATE_LIMIT", "Rate limit for API has reached, try again later."},
+	LA_E_SERVER:                             {"LA_E_SERVER", "Server error."},
+	LA_E_CLIENT:                             {"LA_E_CLIENT", "Client error."},
+}
+
+func lookupStatus(status int) statusDetail {
+	if detail, ok := statusDetails[status]; ok {
+		return detail
+	}
+	return statusDetail{fmt.Sprintf("LA_UNKNOWN_%d", status), "Unknown status code."}
+}
+
+func newStatusError(status int) *StatusError {
+	detail := lookupStatus(status)
+	return &StatusError{Code: status, Name: detail.name, Message: detail.message}
+}
+
+func isInformationalStatus(status int) bool {
+	switch status {
+	case LA_EXPIRED, LA_SUSPENDED, LA_GRACE_PERIOD_OVER,
+		LA_TRIAL_EXPIRED, LA_LOCAL_TRIAL_EXPIRED,
+		LA_RELEASE_UPDATE_AVAILABLE, LA_RELEASE_UPDATE_NOT_AVAILABLE,
+		LA_RELEASE_UPDATE_AVAILABLE_NOT_ALLOWED:
+		return true
+	}
+	return false
+}
+
+// StatusToError converts a status code returned by any function of this
+// package into an error. It returns nil for LA_OK and a *StatusError for
+// every other code, including the informational ones.
+func StatusToError(status int) error {
+	if status == LA_OK {
+		return nil
+	}
+	return newStatusError(status)
+}
+
+// IsInformational reports whether err is a *StatusError for an
+// informational status such as LA_EXPIRED or LA_SUSPENDED. The license or
+// trial is genuine in that case, it is just not in the active state.
+func IsInformational(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.Informational()
+}
+
+// IsFailure reports whether err is a real failure, i.e. it is non-nil and
+// not an informational status. It is the check to use in place of
+// "status != LA_OK" when LA_EXPIRED, LA_SUSPENDED etc. must not be treated
+// as errors.
+func IsFailure(err error) bool {
+	return err != nil && !IsInformational(err)
+}
+
+// Sentinel errors for every status code, usable with errors.Is.
+var (
+	ErrFail                             = newStatusError(LA_FAIL)
+	ErrExpired                          = newStatusError(LA_EXPIRED)
+	ErrSuspended                        = newStatusError(LA_SUSPENDED)
+	ErrGracePeriodOver                  = newStatusError(LA_GRACE_PERIOD_OVER)
+	ErrTrialExpired                     = newStatusError(LA_TRIAL_EXPIRED)
+	ErrLocalTrialExpired                = newStatusError(LA_LOCAL_TRIAL_EXPIRED)
+	ErrReleaseUpdateAvailable           = newStatusError(LA_RELEASE_UPDATE_AVAILABLE)
+	ErrReleaseUpdateNotAvailable        = newStatusError(LA_RELEASE_UPDATE_NOT_AVAILABLE)
+	ErrReleaseUpdateAvailableNotAllowed = newStatusError(LA_RELEASE_UPDATE_AVAILABLE_NOT_ALLOWED)
+	ErrFilePath                         = newStatusError(LA_E_FILE_PATH)
+	ErrProductFile                      = newStatusError(LA_E_PRODUCT_FILE)
+	ErrProductData                      = newStatusError(LA_E_PRODUCT_DATA)
+	ErrProductId                        = newStatusError(LA_E_PRODUCT_ID)
+	ErrSystemPermission                 = newStatusError(LA_E_SYSTEM_PERMISSION)
+	ErrFilePermission                   = newStatusError(LA_E_FILE_PERMISSION)
+	ErrWmic                             = newStatusError(LA_E_WMIC)
+	ErrTime                             = newStatusError(LA_E_TIME)
+	ErrInet                             = newStatusError(LA_E_INET)
+	ErrNetProxy                         = newStatusError(LA_E_NET_PROXY)
+	ErrHostUrl                          = newStatusError(LA_E_HOST_URL)
+	ErrBufferSize                       = newStatusError(LA_E_BUFFER_SIZE)
+	ErrAppVersionLength                 = newStatusError(LA_E_APP_VERSION_LENGTH)
+	ErrRevoked                          = newStatusError(LA_E_REVOKED)
+	ErrLicenseKey                       = newStatusError(LA_E_LICENSE_KEY)
+	ErrLicenseType                      = newStatusError(LA_E_LICENSE_TYPE)
+	ErrOfflineResponseFile              = newStatusError(LA_E_OFFLINE_RESPONSE_FILE)
+	ErrOfflineResponseFileExpired       = newStatusError(LA_E_OFFLINE_RESPONSE_FILE_EXPIRED)
+	ErrActivationLimit                  = newStatusError(LA_E_ACTIVATION_LIMIT)
+	ErrActivationNotFound               = newStatusError(LA_E_ACTIVATION_NOT_FOUND)
+	ErrDeactivationLimit                = newStatusError(LA_E_DEACTIVATION_LIMIT)
+	ErrTrialNotAllowed                  = newStatusError(LA_E_TRIAL_NOT_ALLOWED)
+	ErrTrialActivationLimit             = newStatusError(LA_E_TRIAL_ACTIVATION_LIMIT)
+	ErrMachineFingerprint               = newStatusError(LA_E_MACHINE_FINGERPRINT)
+	ErrMetadataKeyLength                = newStatusError(LA_E_METADATA_KEY_LENGTH)
+	ErrMetadataValueLength              = newStatusError(LA_E_METADATA_VALUE_LENGTH)
+	ErrActivationMetadataLimit          = newStatusError(LA_E_ACTIVATION_METADATA_LIMIT)
+	ErrTrialActivationMetadataLimit     = newStatusError(LA_E_TRIAL_ACTIVATION_METADATA_LIMIT)
+	ErrMetadataKeyNotFound              = newStatusError(LA_E_METADATA_KEY_NOT_FOUND)
+	ErrTimeModified                     = newStatusError(LA_E_TIME_MODIFIED)
+	ErrReleaseVersionFormat             = newStatusError(LA_E_RELEASE_VERSION_FORMAT)
+	ErrAuthenticationFailed             = newStatusError(LA_E_AUTHENTICATION_FAILED)
+	ErrMeterAttributeNotFound           = newStatusError(LA_E_METER_ATTRIBUTE_NOT_FOUND)
+	ErrMeterAttributeUsesLimitReached   = newStatusError(LA_E_METER_ATTRIBUTE_USES_LIMIT_REACHED)
+	ErrCustomFingerprintLength          = newStatusError(LA_E_CUSTOM_FINGERPRINT_LENGTH)
+	ErrProductVersionNotLinked          = newStatusError(LA_E_PRODUCT_VERSION_NOT_LINKED)
+	ErrFeatureFlagNotFound              = newStatusError(LA_E_FEATURE_FLAG_NOT_FOUND)
+	ErrReleaseVersionNotAllowed         = newStatusError(LA_E_RELEASE_VERSION_NOT_ALLOWED)
+	ErrReleasePlatformLength            = newStatusError(LA_E_RELEASE_PLATFORM_LENGTH)
+	ErrReleaseChannelLength             = newStatusError(LA_E_RELEASE_CHANNEL_LENGTH)
+	ErrVM                               = newStatusError(LA_E_VM)
+	ErrCountry                          = newStatusError(LA_E_COUNTRY)
+	ErrIP                               = newStatusError(LA_E_IP)
+	ErrContainer                        = newStatusError(LA_E_CONTAINER)
+	ErrReleaseVersion                   = newStatusError(LA_E_RELEASE_VERSION)
+	ErrReleasePlatform                  = newStatusError(LA_E_RELEASE_PLATFORM)
+	ErrReleaseChannel                   = newStatusError(LA_E_RELEASE_CHANNEL)
+	ErrRateLimit                        = newStatusError(LA_E_RATE_LIMIT)
+	ErrServer                           = newStatusError(LA_E_SERVER)
+	ErrClient                           = newStatusError(LA_E_CLIENT)
+)
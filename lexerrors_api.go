@@ -0,0 +1,345 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package lexactivator
+
+// The functions in this file mirror the status returning API one to one and
+// return the status converted by StatusToError instead. Informational
+// statuses such as LA_EXPIRED are still returned as a *StatusError; use
+// IsFailure to tell them apart from real failures.
+
+// SetProductFileErr calls SetProductFile and returns its status as an error.
+func SetProductFileErr(filePath string) error {
+	return StatusToError(SetProductFile(filePath))
+}
+
+// SetProductDataErr calls SetProductData and returns its status as an error.
+func SetProductDataErr(productData string) error {
+	return StatusToError(SetProductData(productData))
+}
+
+// SetProductIdErr calls SetProductId and returns its status as an error.
+func SetProductIdErr(productId string, flags uint) error {
+	return StatusToError(SetProductId(productId, flags))
+}
+
+// SetDataDirectoryErr calls SetDataDirectory and returns its status as an error.
+func SetDataDirectoryErr(directoryPath string) error {
+	return StatusToError(SetDataDirectory(directoryPath))
+}
+
+// SetCustomDeviceFingerprintErr calls SetCustomDeviceFingerprint and returns its status as an error.
+func SetCustomDeviceFingerprintErr(fingerprint string) error {
+	return StatusToError(SetCustomDeviceFingerprint(fingerprint))
+}
+
+// SetLicenseKeyErr calls SetLicenseKey and returns its status as an error.
+func SetLicenseKeyErr(licenseKey string) error {
+	return StatusToError(SetLicenseKey(licenseKey))
+}
+
+// SetLicenseUserCredentialErr calls SetLicenseUserCredential and returns its status as an error.
+func SetLicenseUserCredentialErr(email string, password string) error {
+	return StatusToError(SetLicenseUserCredential(email, password))
+}
+
+// SetLicenseCallbackErr calls SetLicenseCallback and returns its status as an error.
+func SetLicenseCallbackErr(callbackFunction func(int)) error {
+	return StatusToError(SetLicenseCallback(callbackFunction))
+}
+
+// SetActivationLeaseDurationErr calls SetActivationLeaseDuration and returns its status as an error.
+func SetActivationLeaseDurationErr(leaseDuration uint) error {
+	return StatusToError(SetActivationLeaseDuration(leaseDuration))
+}
+
+// SetActivationMetadataErr calls SetActivationMetadata and returns its status as an error.
+func SetActivationMetadataErr(key string, value string) error {
+	return StatusToError(SetActivationMetadata(key, value))
+}
+
+// SetTrialActivationMetadataErr calls SetTrialActivationMetadata and returns its status as an error.
+func SetTrialActivationMetadataErr(key string, value string) error {
+	return StatusToError(SetTrialActivationMetadata(key, value))
+}
+
+// SetAppVersionErr calls SetAppVersion and returns its status as an error.
+func SetAppVersionErr(appVersion string) error {
+	return StatusToError(SetAppVersion(appVersion))
+}
+
+// SetReleaseVersionErr calls SetReleaseVersion and returns its status as an error.
+func SetReleaseVersionErr(releaseVersion string) error {
+	return StatusToError(SetReleaseVersion(releaseVersion))
+}
+
+// SetReleasePublishedDateErr calls SetReleasePublishedDate and returns its status as an error.
+func SetReleasePublishedDateErr(releasePublishedDate uint) error {
+	return StatusToError(SetReleasePublishedDate(releasePublishedDate))
+}
+
+// SetReleasePlatformErr calls SetReleasePlatform and returns its status as an error.
+func SetReleasePlatformErr(releasePlatform string) error {
+	return StatusToError(SetReleasePlatform(releasePlatform))
+}
+
+// SetReleaseChannelErr calls SetReleaseChannel and returns its status as an error.
+func SetReleaseChannelErr(releaseChannel string) error {
+	return StatusToError(SetReleaseChannel(releaseChannel))
+}
+
+// SetOfflineActivationRequestMeterAttributeUsesErr calls SetOfflineActivationRequestMeterAttributeUses and returns its status as an error.
+func SetOfflineActivationRequestMeterAttributeUsesErr(name string, uses uint) error {
+	return StatusToError(SetOfflineActivationRequestMeterAttributeUses(name, uses))
+}
+
+// SetNetworkProxyErr calls SetNetworkProxy and returns its status as an error.
+func SetNetworkProxyErr(proxy string) error {
+	return StatusToError(SetNetworkProxy(proxy))
+}
+
+// SetCryptlexHostErr calls SetCryptlexHost and returns its status as an error.
+func SetCryptlexHostErr(host string) error {
+	return StatusToError(SetCryptlexHost(host))
+}
+
+// GetProductMetadataErr calls GetProductMetadata and returns its status as an error.
+func GetProductMetadataErr(key string, value *string) error {
+	return StatusToError(GetProductMetadata(key, value))
+}
+
+// GetProductVersionNameErr calls GetProductVersionName and returns its status as an error.
+func GetProductVersionNameErr(name *string) error {
+	return StatusToError(GetProductVersionName(name))
+}
+
+// GetProductVersionDisplayNameErr calls GetProductVersionDisplayName and returns its status as an error.
+func GetProductVersionDisplayNameErr(displayName *string) error {
+	return StatusToError(GetProductVersionDisplayName(displayName))
+}
+
+// GetProductVersionFeatureFlagErr calls GetProductVersionFeatureFlag and returns its status as an error.
+func GetProductVersionFeatureFlagErr(name string, enabled *bool, data *string) error {
+	return StatusToError(GetProductVersionFeatureFlag(name, enabled, data))
+}
+
+// GetLicenseMetadataErr calls GetLicenseMetadata and returns its status as an error.
+func GetLicenseMetadataErr(key string, value *string) error {
+	return StatusToError(GetLicenseMetadata(key, value))
+}
+
+// GetLicenseMeterAttributeErr calls GetLicenseMeterAttribute and returns its status as an error.
+func GetLicenseMeterAttributeErr(name string, allowedUses *uint, totalUses *uint, grossUses *uint) error {
+	return StatusToError(GetLicenseMeterAttribute(name, allowedUses, totalUses, grossUses))
+}
+
+// GetLicenseKeyErr calls GetLicenseKey and returns its status as an error.
+func GetLicenseKeyErr(licenseKey *string) error {
+	return StatusToError(GetLicenseKey(licenseKey))
+}
+
+// GetLicenseAllowedActivationsErr calls GetLicenseAllowedActivations and returns its status as an error.
+func GetLicenseAllowedActivationsErr(allowedActivations *uint) error {
+	return StatusToError(GetLicenseAllowedActivations(allowedActivations))
+}
+
+// GetLicenseTotalActivationsErr calls GetLicenseTotalActivations and returns its status as an error.
+func GetLicenseTotalActivationsErr(totalActivations *uint) error {
+	return StatusToError(GetLicenseTotalActivations(totalActivations))
+}
+
+// GetLicenseExpiryDateErr calls GetLicenseExpiryDate and returns its status as an error.
+func GetLicenseExpiryDateErr(expiryDate *uint) error {
+	return StatusToError(GetLicenseExpiryDate(expiryDate))
+}
+
+// GetLicenseMaintenanceExpiryDateErr calls GetLicenseMaintenanceExpiryDate and returns its status as an error.
+func GetLicenseMaintenanceExpiryDateErr(maintenanceExpiryDate *uint) error {
+	return StatusToError(GetLicenseMaintenanceExpiryDate(maintenanceExpiryDate))
+}
+
+// GetLicenseMaxAllowedReleaseVersionErr calls GetLicenseMaxAllowedReleaseVersion and returns its status as an error.
+func GetLicenseMaxAllowedReleaseVersionErr(maxAllowedReleaseVersion *string) error {
+	return StatusToError(GetLicenseMaxAllowedReleaseVersion(maxAllowedReleaseVersion))
+}
+
+// GetLicenseUserEmailErr calls GetLicenseUserEmail and returns its status as an error.
+func GetLicenseUserEmailErr(email *string) error {
+	return StatusToError(GetLicenseUserEmail(email))
+}
+
+// GetLicenseUserNameErr calls GetLicenseUserName and returns its status as an error.
+func GetLicenseUserNameErr(name *string) error {
+	return StatusToError(GetLicenseUserName(name))
+}
+
+// GetLicenseUserCompanyErr calls GetLicenseUserCompany and returns its status as an error.
+func GetLicenseUserCompanyErr(company *string) error {
+	return StatusToError(GetLicenseUserCompany(company))
+}
+
+// GetLicenseUserMetadataErr calls GetLicenseUserMetadata and returns its status as an error.
+func GetLicenseUserMetadataErr(key string, value *string) error {
+	return StatusToError(GetLicenseUserMetadata(key, value))
+}
+
+// GetLicenseOrganizationNameErr calls GetLicenseOrganizationName and returns its status as an error.
+func GetLicenseOrganizationNameErr(organizationName *string) error {
+	return StatusToError(GetLicenseOrganizationName(organizationName))
+}
+
+// GetLicenseOrganizationAddressErr calls GetLicenseOrganizationAddress and returns its status as an error,
+// or the *DecodeError of an address that cannot be decoded.
+func GetLicenseOrganizationAddressErr(organizationAddress *OrganizationAddress) error {
+	_, err := getLicenseOrganizationAddress(organizationAddress)
+	return err
+}
+
+// GetLicenseTypeErr calls GetLicenseType and returns its status as an error.
+func GetLicenseTypeErr(licenseType *string) error {
+	return StatusToError(GetLicenseType(licenseType))
+}
+
+// GetActivationMetadataErr calls GetActivationMetadata and returns its status as an error.
+func GetActivationMetadataErr(key string, value *string) error {
+	return StatusToError(GetActivationMetadata(key, value))
+}
+
+// GetActivationModeErr calls GetActivationMode and returns its status as an error.
+func GetActivationModeErr(initialMode *string, currentMode *string) error {
+	return StatusToError(GetActivationMode(initialMode, currentMode))
+}
+
+// GetActivationMeterAttributeUsesErr calls GetActivationMeterAttributeUses and returns its status as an error.
+func GetActivationMeterAttributeUsesErr(name string, uses *uint) error {
+	return StatusToError(GetActivationMeterAttributeUses(name, uses))
+}
+
+// GetServerSyncGracePeriodExpiryDateErr calls GetServerSyncGracePeriodExpiryDate and returns its status as an error.
+func GetServerSyncGracePeriodExpiryDateErr(expiryDate *uint) error {
+	return StatusToError(GetServerSyncGracePeriodExpiryDate(expiryDate))
+}
+
+// GetTrialActivationMetadataErr calls GetTrialActivationMetadata and returns its status as an error.
+func GetTrialActivationMetadataErr(key string, value *string) error {
+	return StatusToError(GetTrialActivationMetadata(key, value))
+}
+
+// GetTrialExpiryDateErr calls GetTrialExpiryDate and returns its status as an error.
+func GetTrialExpiryDateErr(trialExpiryDate *uint) error {
+	return StatusToError(GetTrialExpiryDate(trialExpiryDate))
+}
+
+// GetTrialIdErr calls GetTrialId and returns its status as an error.
+func GetTrialIdErr(trialId *string) error {
+	return StatusToError(GetTrialId(trialId))
+}
+
+// GetLocalTrialExpiryDateErr calls GetLocalTrialExpiryDate and returns its status as an error.
+func GetLocalTrialExpiryDateErr(trialExpiryDate *uint) error {
+	return StatusToError(GetLocalTrialExpiryDate(trialExpiryDate))
+}
+
+// GetLibraryVersionErr calls GetLibraryVersion and returns its status as an error.
+func GetLibraryVersionErr(libraryVersion *string) error {
+	return StatusToError(GetLibraryVersion(libraryVersion))
+}
+
+// CheckForReleaseUpdateErr calls CheckForReleaseUpdate and returns its status as an error.
+func CheckForReleaseUpdateErr(platform string, version string, channel string, callbackFunction func(int)) error {
+	return StatusToError(CheckForReleaseUpdate(platform, version, channel, callbackFunction))
+}
+
+// CheckReleaseUpdateErr calls CheckReleaseUpdate and returns its status as an error.
+func CheckReleaseUpdateErr(releaseUpdateCallbackFunction func(int, *Release, interface{}), releaseFlags uint, userData interface{}) error {
+	return StatusToError(CheckReleaseUpdate(releaseUpdateCallbackFunction, releaseFlags, userData))
+}
+
+// ActivateLicenseErr calls ActivateLicense and returns its status as an error.
+func ActivateLicenseErr() error {
+	return StatusToError(ActivateLicense())
+}
+
+// ActivateLicenseOfflineErr calls ActivateLicenseOffline and returns its status as an error.
+func ActivateLicenseOfflineErr(filePath string) error {
+	return StatusToError(ActivateLicenseOffline(filePath))
+}
+
+// GenerateOfflineActivationRequestErr calls GenerateOfflineActivationRequest and returns its status as an error.
+func GenerateOfflineActivationRequestErr(filePath string) error {
+	return StatusToError(GenerateOfflineActivationRequest(filePath))
+}
+
+// DeactivateLicenseErr calls DeactivateLicense and returns its status as an error.
+func DeactivateLicenseErr() error {
+	return StatusToError(DeactivateLicense())
+}
+
+// GenerateOfflineDeactivationRequestErr calls GenerateOfflineDeactivationRequest and returns its status as an error.
+func GenerateOfflineDeactivationRequestErr(filePath string) error {
+	return StatusToError(GenerateOfflineDeactivationRequest(filePath))
+}
+
+// IsLicenseGenuineErr calls IsLicenseGenuine and returns its status as an error.
+func IsLicenseGenuineErr() error {
+	return StatusToError(IsLicenseGenuine())
+}
+
+// IsLicenseValidErr calls IsLicenseValid and returns its status as an error.
+func IsLicenseValidErr() error {
+	return StatusToError(IsLicenseValid())
+}
+
+// ActivateTrialErr calls ActivateTrial and returns its status as an error.
+func ActivateTrialErr() error {
+	return StatusToError(ActivateTrial())
+}
+
+// ActivateTrialOfflineErr calls ActivateTrialOffline and returns its status as an error.
+func ActivateTrialOfflineErr(filePath string) error {
+	return StatusToError(ActivateTrialOffline(filePath))
+}
+
+// GenerateOfflineTrialActivationRequestErr calls GenerateOfflineTrialActivationRequest and returns its status as an error.
+func GenerateOfflineTrialActivationRequestErr(filePath string) error {
+	return StatusToError(GenerateOfflineTrialActivationRequest(filePath))
+}
+
+// IsTrialGenuineErr calls IsTrialGenuine and returns its status as an error.
+func IsTrialGenuineErr() error {
+	return StatusToError(IsTrialGenuine())
+}
+
+// ActivateLocalTrialErr calls ActivateLocalTrial and returns its status as an error.
+func ActivateLocalTrialErr(trialLength uint) error {
+	return StatusToError(ActivateLocalTrial(trialLength))
+}
+
+// IsLocalTrialGenuineErr calls IsLocalTrialGenuine and returns its status as an error.
+func IsLocalTrialGenuineErr() error {
+	return StatusToError(IsLocalTrialGenuine())
+}
+
+// ExtendLocalTrialErr calls ExtendLocalTrial and returns its status as an error.
+func ExtendLocalTrialErr(trialExtensionLength uint) error {
+	return StatusToError(ExtendLocalTrial(trialExtensionLength))
+}
+
+// IncrementActivationMeterAttributeUsesErr calls IncrementActivationMeterAttributeUses and returns its status as an error.
+func IncrementActivationMeterAttributeUsesErr(name string, increment uint) error {
+	return StatusToError(IncrementActivationMeterAttributeUses(name, increment))
+}
+
+// DecrementActivationMeterAttributeUsesErr calls DecrementActivationMeterAttributeUses and returns its status as an error.
+func DecrementActivationMeterAttributeUsesErr(name string, decrement uint) error {
+	return StatusToError(DecrementActivationMeterAttributeUses(name, decrement))
+}
+
+// ResetActivationMeterAttributeUsesErr calls ResetActivationMeterAttributeUses and returns its status as an error.
+func ResetActivationMeterAttributeUsesErr(name string) error {
+	return StatusToError(ResetActivationMeterAttributeUses(name))
+}
+
+// ResetErr calls Reset and returns its status as an error.
+func ResetErr() error {
+	return StatusToError(Reset())
+}
@@ -0,0 +1,219 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package lexactivator
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LicenseEvent is the result of a server sync along with the license state
+// right after it.
+type LicenseEvent struct {
+	// Status is the status the license callback was invoked with, e.g.
+	// LA_OK, LA_EXPIRED, LA_SUSPENDED, LA_E_REVOKED or LA_E_INET.
+	Status Status
+
+	// Time is the time the library reported the sync result.
+	Time time.Time
+
+	License LicenseSnapshot
+}
+
+// LicenseSnapshot is the license state read when a LicenseEvent is
+// delivered.
+type LicenseSnapshot struct {
+	// ExpiryTime is the license expiry time. Expires is false for a license
+	// that never expires.
+	ExpiryTime time.Time
+	Expires    bool
+
+	Type        string
+	InitialMode Mode
+	CurrentMode Mode
+
+	// Err is the first error reading the license state, e.g. after the
+	// license was revoked. The fields that could not be read are zero.
+	Err error
+}
+
+// licenseEventBuffer is the number of events buffered per subscriber, and
+// queued for delivery by the hub. When a subscriber or the delivery falls
+// behind, the oldest event is dropped.
+const licenseEventBuffer = 16
+
+// licenseEventHub fans the results of server syncs out to any number of
+// subscribers. Results are queued by publish(), which never blocks the
+// native thread invoking the license callback, and delivered in order by a
+// single goroutine, which reads the license snapshot.
+type licenseEventHub struct {
+	backend Backend
+
+	// register installs the hub with the library. It is retried on
+	// every subscription until it succeeds, as it fails before the
+	// product id is set.
+	register func() int
+
+	mu          sync.Mutex
+	registered  bool
+	callback    callbackType
+	subscribers map[chan LicenseEvent]struct{}
+	queue       []LicenseEvent
+	delivering  bool
+}
+
+func newLicenseEventHub(backend Backend) *licenseEventHub {
+	return &licenseEventHub{
+		backend:     backend,
+		subscribers: map[chan LicenseEvent]struct{}{},
+	}
+}
+
+// nativeEvents is the hub of LicenseEvents(). The native license callback
+// publishes to it whether or not a callback is set by SetLicenseCallback().
+var nativeEvents = func() *licenseEventHub {
+	hub := newLicenseEventHub(Native())
+	hub.register = registerLicenseCallback
+	return hub
+}()
+
+// LicenseEvents returns a channel receiving an event for every server sync
+// result, for as long as ctx is not done. The channel is closed once ctx is
+// done. Any number of subscribers can receive events alongside the
+// callback set by SetLicenseCallback().
+//
+// LicenseEvents must be called after SetProductData() or SetProductFile()
+// and SetProductId(), before which the library does not accept callbacks:
+// the error of registering the callback, e.g. ErrProductId, is returned
+// and no channel is created. Receivers that fall behind lose their oldest
+// events.
+func LicenseEvents(ctx context.Context) (<-chan LicenseEvent, error) {
+	return nativeEvents.subscribe(ctx)
+}
+
+func (h *licenseEventHub) subscribe(ctx context.Context) (<-chan LicenseEvent, error) {
+	if err := StatusToError(h.ensureRegistered()); err != nil {
+		return nil, err
+	}
+	events := make(chan LicenseEvent, licenseEventBuffer)
+	h.mu.Lock()
+	h.subscribers[events] = struct{}{}
+	h.mu.Unlock()
+	go func() {
+		<-ctx.Done()
+		h.mu.Lock()
+		delete(h.subscribers, events)
+		close(events)
+		h.mu.Unlock()
+	}()
+	return events, nil
+}
+
+// ensureRegistered registers the hub with the library unless it already is.
+func (h *licenseEventHub) ensureRegistered() int {
+	h.mu.Lock()
+	registered := h.registered
+	h.mu.Unlock()
+	if registered {
+		return LA_OK
+	}
+	status := h.register()
+	if status == LA_OK {
+		h.mu.Lock()
+		h.registered = true
+		h.mu.Unlock()
+	}
+	return status
+}
+
+// setCallback sets the callback dispatch() invokes along with publishing
+// the event, and registers the hub with the library.
+func (h *licenseEventHub) setCallback(callback callbackType) int {
+	h.mu.Lock()
+	h.callback = callback
+	h.registered = false
+	h.mu.Unlock()
+	return h.ensureRegistered()
+}
+
+// dispatch is the license callback of hubs registered through a Backend.
+func (h *licenseEventHub) dispatch(status int) {
+	h.publish(status)
+	h.mu.Lock()
+	callback := h.callback
+	h.mu.Unlock()
+	if callback != nil {
+		defer recoverCallback("license")
+		callback(status)
+	}
+}
+
+func (h *licenseEventHub) publish(status int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.subscribers) == 0 {
+		return
+	}
+	if len(h.queue) == licenseEventBuffer {
+		h.queue = h.queue[1:]
+	}
+	h.queue = append(h.queue, LicenseEvent{Status: Status(status), Time: time.Now()})
+	if !h.delivering {
+		h.delivering = true
+		go h.deliver()
+	}
+}
+
+func (h *licenseEventHub) deliver() {
+	for {
+		h.mu.Lock()
+		if len(h.queue) == 0 {
+			h.delivering = false
+			h.mu.Unlock()
+			return
+		}
+		event := h.queue[0]
+		h.queue = h.queue[1:]
+		h.mu.Unlock()
+
+		event.License = h.snapshot()
+
+		h.mu.Lock()
+		for events := range h.subscribers {
+			select {
+			case events <- event:
+			default:
+				select {
+				case <-events:
+				default:
+				}
+				events <- event
+			}
+		}
+		h.mu.Unlock()
+	}
+}
+
+func (h *licenseEventHub) snapshot() LicenseSnapshot {
+	var snapshot LicenseSnapshot
+	check := func(status int) bool {
+		if status != LA_OK && snapshot.Err == nil {
+			snapshot.Err = StatusToError(status)
+		}
+		return status == LA_OK
+	}
+	var expiryDate uint
+	if check(h.backend.GetLicenseExpiryDate(&expiryDate)) {
+		snapshot.ExpiryTime, snapshot.Expires = unixTime(expiryDate)
+	}
+	var licenseType string
+	if check(h.backend.GetLicenseType(&licenseType)) {
+		snapshot.Type = licenseType
+	}
+	var initialMode, currentMode string
+	if check(h.backend.GetActivationMode(&initialMode, &currentMode)) {
+		snapshot.InitialMode, snapshot.CurrentMode = Mode(initialMode), Mode(currentMode)
+	}
+	return snapshot
+}
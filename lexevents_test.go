@@ -0,0 +1,21 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package lexactivator
+
+import "testing"
+
+func TestLicenseEventQueueBounded(t *testing.T) {
+	hub := newLicenseEventHub(nil)
+	hub.subscribers[make(chan LicenseEvent, licenseEventBuffer)] = struct{}{}
+	// Hold the delivery back, as a slow license snapshot would.
+	hub.delivering = true
+	for status := 0; status < 100; status++ {
+		hub.publish(status)
+	}
+	if len(hub.queue) != licenseEventBuffer {
+		t.Fatalf("%d events queued, want %d", len(hub.queue), licenseEventBuffer)
+	}
+	if first, last := hub.queue[0].Status, hub.queue[len(hub.queue)-1].Status; int(first) != 100-licenseEventBuffer || int(last) != 99 {
+		t.Fatalf("queued statuses %d to %d, want the latest ones", first, last)
+	}
+}
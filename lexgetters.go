@@ -0,0 +1,211 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package lexactivator
+
+// The functions in this file return the value of the corresponding Get*
+// function instead of writing it through an out pointer. The returned
+// value is only meaningful when the error is nil.
+
+// ProductMetadata returns the product metadata as set in the dashboard.
+func ProductMetadata(key string) (string, error) {
+	var value string
+	status := GetProductMetadata(key, &value)
+	return value, StatusToError(status)
+}
+
+// ProductVersionName returns the product version name.
+func ProductVersionName() (string, error) {
+	var name string
+	status := GetProductVersionName(&name)
+	return name, StatusToError(status)
+}
+
+// ProductVersionDisplayName returns the product version display name.
+func ProductVersionDisplayName() (string, error) {
+	var displayName string
+	status := GetProductVersionDisplayName(&displayName)
+	return displayName, StatusToError(status)
+}
+
+// ProductVersionFeatureFlag returns the product version feature flag.
+func ProductVersionFeatureFlag(name string) (FeatureFlag, error) {
+	flag := FeatureFlag{Name: name}
+	status := GetProductVersionFeatureFlag(name, &flag.Enabled, &flag.Data)
+	return flag, StatusToError(status)
+}
+
+// LicenseMetadata returns the license metadata as set in the dashboard.
+func LicenseMetadata(key string) (string, error) {
+	var value string
+	status := GetLicenseMetadata(key, &value)
+	return value, StatusToError(status)
+}
+
+// LicenseMeterAttribute returns the license meter attribute allowed, total
+// and gross uses.
+func LicenseMeterAttribute(name string) (MeterAttribute, error) {
+	attribute := MeterAttribute{Name: name}
+	status := GetLicenseMeterAttribute(name, &attribute.AllowedUses, &attribute.TotalUses, &attribute.GrossUses)
+	return attribute, StatusToError(status)
+}
+
+// LicenseKey returns the license key used for activation.
+func LicenseKey() (string, error) {
+	var licenseKey string
+	status := GetLicenseKey(&licenseKey)
+	return licenseKey, StatusToError(status)
+}
+
+// LicenseAllowedActivations returns the allowed activations of the license.
+func LicenseAllowedActivations() (uint, error) {
+	var allowedActivations uint
+	status := GetLicenseAllowedActivations(&allowedActivations)
+	return allowedActivations, StatusToError(status)
+}
+
+// LicenseTotalActivations returns the total activations of the license.
+func LicenseTotalActivations() (uint, error) {
+	var totalActivations uint
+	status := GetLicenseTotalActivations(&totalActivations)
+	return totalActivations, StatusToError(status)
+}
+
+// LicenseExpiryDate returns the license expiry date timestamp.
+func LicenseExpiryDate() (uint, error) {
+	var expiryDate uint
+	status := GetLicenseExpiryDate(&expiryDate)
+	return expiryDate, StatusToError(status)
+}
+
+// LicenseMaintenanceExpiryDate returns the license maintenance expiry date
+// timestamp.
+func LicenseMaintenanceExpiryDate() (uint, error) {
+	var maintenanceExpiryDate uint
+	status := GetLicenseMaintenanceExpiryDate(&maintenanceExpiryDate)
+	return maintenanceExpiryDate, StatusToError(status)
+}
+
+// LicenseMaxAllowedReleaseVersion returns the maximum allowed release
+// version of the license.
+func LicenseMaxAllowedReleaseVersion() (string, error) {
+	var maxAllowedReleaseVersion string
+	status := GetLicenseMaxAllowedReleaseVersion(&maxAllowedReleaseVersion)
+	return maxAllowedReleaseVersion, StatusToError(status)
+}
+
+// LicenseUserEmail returns the email associated with the license user.
+func LicenseUserEmail() (string, error) {
+	var email string
+	status := GetLicenseUserEmail(&email)
+	return email, StatusToError(status)
+}
+
+// LicenseUserName returns the name associated with the license user.
+func LicenseUserName() (string, error) {
+	var name string
+	status := GetLicenseUserName(&name)
+	return name, StatusToError(status)
+}
+
+// LicenseUserCompany returns the company associated with the license user.
+func LicenseUserCompany() (string, error) {
+	var company string
+	status := GetLicenseUserCompany(&company)
+	return company, StatusToError(status)
+}
+
+// LicenseUserMetadata returns the metadata associated with the license user.
+func LicenseUserMetadata(key string) (string, error) {
+	var value string
+	status := GetLicenseUserMetadata(key, &value)
+	return value, StatusToError(status)
+}
+
+// LicenseOrganizationName returns the organization name associated with the
+// license.
+func LicenseOrganizationName() (string, error) {
+	var organizationName string
+	status := GetLicenseOrganizationName(&organizationName)
+	return organizationName, StatusToError(status)
+}
+
+// LicenseOrganizationAddress returns the organization address associated
+// with the license. A *DecodeError is returned if the address could not be
+// decoded.
+func LicenseOrganizationAddress() (OrganizationAddress, error) {
+	var organizationAddress OrganizationAddress
+	_, err := getLicenseOrganizationAddress(&organizationAddress)
+	return organizationAddress, err
+}
+
+// LicenseType returns the license type (node-locked or hosted-floating).
+func LicenseType() (string, error) {
+	var licenseType string
+	status := GetLicenseType(&licenseType)
+	return licenseType, StatusToError(status)
+}
+
+// ActivationMetadata returns the activation metadata.
+func ActivationMetadata(key string) (string, error) {
+	var value string
+	status := GetActivationMetadata(key, &value)
+	return value, StatusToError(status)
+}
+
+// ActivationMode returns the initial and current mode of activation.
+func ActivationMode() (initial, current Mode, err error) {
+	var initialMode, currentMode string
+	err = StatusToError(GetActivationMode(&initialMode, &currentMode))
+	return Mode(initialMode), Mode(currentMode), err
+}
+
+// ActivationMeterAttributeUses returns the meter attribute uses consumed by
+// the activation.
+func ActivationMeterAttributeUses(name string) (uint, error) {
+	var uses uint
+	status := GetActivationMeterAttributeUses(name, &uses)
+	return uses, StatusToError(status)
+}
+
+// ServerSyncGracePeriodExpiryDate returns the server sync grace period
+// expiry date timestamp.
+func ServerSyncGracePeriodExpiryDate() (uint, error) {
+	var expiryDate uint
+	status := GetServerSyncGracePeriodExpiryDate(&expiryDate)
+	return expiryDate, StatusToError(status)
+}
+
+// TrialActivationMetadata returns the trial activation metadata.
+func TrialActivationMetadata(key string) (string, error) {
+	var value string
+	status := GetTrialActivationMetadata(key, &value)
+	return value, StatusToError(status)
+}
+
+// TrialExpiryDate returns the trial expiry date timestamp.
+func TrialExpiryDate() (uint, error) {
+	var trialExpiryDate uint
+	status := GetTrialExpiryDate(&trialExpiryDate)
+	return trialExpiryDate, StatusToError(status)
+}
+
+// TrialId returns the trial activation id. Used in case of trial extension.
+func TrialId() (string, error) {
+	var trialId string
+	status := GetTrialId(&trialId)
+	return trialId, StatusToError(status)
+}
+
+// LocalTrialExpiryDate returns the local trial expiry date timestamp.
+func LocalTrialExpiryDate() (uint, error) {
+	var trialExpiryDate uint
+	status := GetLocalTrialExpiryDate(&trialExpiryDate)
+	return trialExpiryDate, StatusToError(status)
+}
+
+// LibraryVersion returns the version of the LexActivator library.
+func LibraryVersion() (string, error) {
+	var libraryVersion string
+	status := GetLibraryVersion(&libraryVersion)
+	return libraryVersion, StatusToError(status)
+}
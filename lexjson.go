@@ -0,0 +1,91 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package lexactivator
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+)
+
+// DecodeMode controls how the JSON returned by the native library for
+// releases and organization addresses is decoded.
+type DecodeMode int32
+
+const (
+	// DecodeLenient ignores the fields the Go structs do not model. They
+	// remain available in the Raw field of the result. This is the default.
+	DecodeLenient DecodeMode = iota
+
+	// DecodeStrict reports the fields the Go structs do not model as
+	// decode errors, e.g. to catch schema changes in tests.
+	DecodeStrict
+)
+
+var decodeMode int32 = int32(DecodeLenient)
+
+// SetDecodeMode sets the mode used to decode releases and organization
+// addresses.
+func SetDecodeMode(mode DecodeMode) {
+	atomic.StoreInt32(&decodeMode, int32(mode))
+}
+
+func currentDecodeMode() DecodeMode {
+	return DecodeMode(atomic.LoadInt32(&decodeMode))
+}
+
+// DecodeError is returned when the JSON returned by the native library
+// cannot be decoded into the Go struct. The fields that could be decoded
+// are still set on the result.
+type DecodeError struct {
+	// Type is the name of the Go type decoded into, e.g. "Release".
+	Type string
+
+	// JSON is the JSON that failed to decode.
+	JSON string
+
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("lexactivator: decoding %s: %v", e.Type, e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// decodeJSON decodes data into v in the current decode mode. Type errors
+// are reported after the rest of data is decoded, like json.Unmarshal()
+// does.
+func decodeJSON(data []byte, v interface{}, typeName string) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if currentDecodeMode() == DecodeStrict {
+		decoder.DisallowUnknownFields()
+	}
+	err := decoder.Decode(v)
+	if err == nil && decoder.More() {
+		err = fmt.Errorf("unexpected data after the top-level value")
+	}
+	if err != nil {
+		return &DecodeError{Type: typeName, JSON: string(data), Err: err}
+	}
+	return nil
+}
+
+// decodeOrganizationAddress decodes the organization address JSON returned
+// by the native library into organizationAddress.
+func decodeOrganizationAddress(organizationAddressJson string, organizationAddress *OrganizationAddress) error {
+	raw := json.RawMessage(organizationAddressJson)
+	err := decodeJSON(raw, organizationAddress, "OrganizationAddress")
+	organizationAddress.Raw = raw
+	return err
+}
+
+// organizationAddressGetter is implemented by backends able to report the
+// errors decoding organization addresses, which the signature of
+// GetLicenseOrganizationAddress() cannot.
+type organizationAddressGetter interface {
+	getLicenseOrganizationAddress(organizationAddress *OrganizationAddress) (int, error)
+}
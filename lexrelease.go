@@ -0,0 +1,134 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package lexactivator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// ReleaseCheckResult is the result of a release update check.
+type ReleaseCheckResult struct {
+	// Status is LA_RELEASE_UPDATE_AVAILABLE, LA_RELEASE_UPDATE_NOT_AVAILABLE
+	// or LA_RELEASE_UPDATE_AVAILABLE_NOT_ALLOWED once the check completed,
+	// or the status the check failed with.
+	Status Status
+
+	// Release is the latest available release, if any.
+	Release *Release
+
+	// Err is the error of the status the check failed with, the context
+	// error if the context was done first, or the error decoding the
+	// release. It is nil for a completed check with a decoded release.
+	Err error
+}
+
+// releaseChecker is implemented by backends able to report the errors
+// decoding releases, which the signature of CheckReleaseUpdate() cannot.
+type releaseChecker interface {
+	checkReleaseUpdate(releaseFlags uint, callback releaseCheckCallback) int
+}
+
+// CheckReleaseUpdateAsync checks whether a new release is available for the
+// product, see CheckReleaseUpdate(). The returned channel receives exactly
+// one result and is then closed. Any number of checks may run concurrently.
+func CheckReleaseUpdateAsync(ctx context.Context, releaseFlags uint) <-chan ReleaseCheckResult {
+	return checkReleaseAsync(ctx, func(callback releaseCheckCallback) int {
+		return checkReleaseUpdate(releaseFlags, callback)
+	})
+}
+
+// CheckRelease checks whether a new release is available for the product
+// and waits for the result until ctx is done. The returned error is the Err
+// of the result.
+func CheckRelease(ctx context.Context, releaseFlags uint) (ReleaseCheckResult, error) {
+	result := <-CheckReleaseUpdateAsync(ctx, releaseFlags)
+	return result, result.Err
+}
+
+// CheckReleaseUpdateAsync checks whether a new release is available for the
+// product, see CheckReleaseUpdateAsync().
+func (c *Client) CheckReleaseUpdateAsync(ctx context.Context, releaseFlags uint) <-chan ReleaseCheckResult {
+	return checkReleaseAsync(ctx, func(callback releaseCheckCallback) int {
+		if checker, ok := c.backend.(releaseChecker); ok {
+			return checker.checkReleaseUpdate(releaseFlags, callback)
+		}
+		return c.backend.CheckReleaseUpdate(func(status int, release *Release, _ interface{}) {
+			callback(status, release, nil)
+		}, releaseFlags, nil)
+	})
+}
+
+// CheckRelease checks whether a new release is available for the product
+// and waits for the result until ctx is done, see CheckRelease().
+func (c *Client) CheckRelease(ctx context.Context, releaseFlags uint) (ReleaseCheckResult, error) {
+	result := <-c.CheckReleaseUpdateAsync(ctx, releaseFlags)
+	return result, result.Err
+}
+
+// checkReleaseAsync starts a check with start and delivers the first of
+// its result, its failure to start and ctx being done.
+func checkReleaseAsync(ctx context.Context, start func(releaseCheckCallback) int) <-chan ReleaseCheckResult {
+	results := make(chan ReleaseCheckResult, 1)
+	delivered := make(chan struct{})
+	var once sync.Once
+	deliver := func(result ReleaseCheckResult) {
+		once.Do(func() {
+			results <- result
+			close(results)
+			close(delivered)
+		})
+	}
+	if err := ctx.Err(); err != nil {
+		deliver(ReleaseCheckResult{Status: Status(LA_FAIL), Err: err})
+		return results
+	}
+	status := start(func(status int, release *Release, err error) {
+		deliver(ReleaseCheckResult{Status: Status(status), Release: release, Err: releaseCheckError(status, err)})
+	})
+	if status != LA_OK {
+		deliver(ReleaseCheckResult{Status: Status(status), Err: StatusToError(status)})
+		return results
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			deliver(ReleaseCheckResult{Status: Status(LA_FAIL), Err: ctx.Err()})
+		case <-delivered:
+		}
+	}()
+	return results
+}
+
+// releaseCheckError returns the error of a check the callback was invoked
+// for with status and the error decoding the release, if any.
+func releaseCheckError(status int, decodeErr error) error {
+	switch status {
+	case LA_RELEASE_UPDATE_AVAILABLE, LA_RELEASE_UPDATE_AVAILABLE_NOT_ALLOWED, LA_RELEASE_UPDATE_NOT_AVAILABLE:
+		return decodeErr
+	}
+	err := StatusToError(status)
+	switch {
+	case err == nil:
+		return decodeErr
+	case decodeErr != nil:
+		return fmt.Errorf("%w (%v)", err, decodeErr)
+	}
+	return err
+}
+
+// decodeRelease decodes the release JSON passed to the release update
+// callback. An empty string is no release. On a decode error, the fields
+// that could be decoded are set on the release.
+func decodeRelease(releaseJson string) (*Release, error) {
+	if releaseJson == "" {
+		return nil, nil
+	}
+	release := &Release{}
+	raw := json.RawMessage(releaseJson)
+	err := decodeJSON(raw, release, "Release")
+	release.Raw = raw
+	return release, err
+}
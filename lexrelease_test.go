@@ -0,0 +1,61 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package lexactivator_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+	"github.com/Exostellar/lexactivator-go/fake"
+)
+
+func newReleaseClient(t *testing.T, b *fake.Backend) *lexactivator.Client {
+	t.Helper()
+	client, err := lexactivator.New(lexactivator.Config{
+		Backend:         b,
+		ProductData:     "data",
+		ProductId:       "id",
+		ReleaseVersion:  "1.0.0",
+		ReleasePlatform: "linux",
+		ReleaseChannel:  "stable",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestCheckReleaseCallbackStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		err    error
+	}{
+		{lexactivator.LA_RELEASE_UPDATE_AVAILABLE, nil},
+		{lexactivator.LA_RELEASE_UPDATE_AVAILABLE_NOT_ALLOWED, nil},
+		{lexactivator.LA_E_INET, lexactivator.ErrInet},
+		{lexactivator.LA_E_SERVER, lexactivator.ErrServer},
+	}
+	for _, test := range tests {
+		b := fake.New()
+		client := newReleaseClient(t, b)
+		b.Update(func(s *fake.State) {
+			s.Release = &lexactivator.Release{Version: "2.0.0"}
+			s.ReleaseStatus = test.status
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		result, err := client.CheckRelease(ctx, lexactivator.LA_RELEASES_ALLOWED)
+		cancel()
+		if int(result.Status) != test.status {
+			t.Errorf("%s: result status %s", lexactivator.Status(test.status), result.Status)
+		}
+		if test.err == nil && err != nil || test.err != nil && !errors.Is(err, test.err) {
+			t.Errorf("%s: error %v, want %v", lexactivator.Status(test.status), err, test.err)
+		}
+		if result.Err != err {
+			t.Errorf("%s: result error %v, returned %v", lexactivator.Status(test.status), result.Err, err)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package lexactivator
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy retries the calls of a Client that fail with a transient
+// status: ActivateLicense(), ActivateTrial() and the activation meter
+// attribute functions, along with their ...Context() variants. It is
+// enabled by setting Config.Retry.
+//
+// The delay before the nth retry is InitialBackoff * Multiplier^(n-1),
+// capped at MaxBackoff, and randomized by +/- Jitter. After LA_E_RATE_LIMIT
+// the delay is at least RateLimitBackoff.
+//
+// IncrementActivationMeterAttributeUses() and
+// DecrementActivationMeterAttributeUses() are not idempotent, so they are
+// only retried after the statuses for which Status.IsSafeToResend() holds,
+// whatever Retryable reports.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first
+	// one. Defaults to 3.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry. Defaults to 1s.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between attempts. Defaults to 30s.
+	MaxBackoff time.Duration
+
+	// Multiplier is the factor the delay grows by after every retry.
+	// Defaults to 2.
+	Multiplier float64
+
+	// Jitter is the fraction, between 0 and 1, by which delays are
+	// randomized so that clients do not retry in lockstep. Defaults to
+	// 0.2; use a negative value to disable jitter.
+	Jitter float64
+
+	// RateLimitBackoff is the minimum delay after LA_E_RATE_LIMIT.
+	// Defaults to 60s.
+	RateLimitBackoff time.Duration
+
+	// Retryable reports whether a status is worth retrying. Defaults to
+	// Status.IsRetryable().
+	Retryable func(Status) bool
+
+	// OnRetry is called before waiting for every retry.
+	OnRetry func(RetryAttempt)
+}
+
+// RetryAttempt describes a failed attempt about to be retried.
+type RetryAttempt struct {
+	// Call is the name of the function, e.g. "ActivateLicense".
+	Call string
+
+	// Attempt is the number of the failed attempt, starting at 1.
+	Attempt int
+
+	// Err is the error of the failed attempt.
+	Err error
+
+	// Delay is the time waited before the next attempt.
+	Delay time.Duration
+}
+
+func (policy *RetryPolicy) validate() error {
+	switch {
+	case policy.MaxAttempts < 0:
+		return errors.New("MaxAttempts must not be negative")
+	case policy.InitialBackoff < 0 || policy.MaxBackoff < 0 || policy.RateLimitBackoff < 0:
+		return errors.New("backoffs must not be negative")
+	case policy.Multiplier != 0 && policy.Multiplier < 1:
+		return errors.New("Multiplier must be at least 1")
+	case policy.Jitter > 1:
+		return errors.New("Jitter must be at most 1")
+	}
+	return nil
+}
+
+// withDefaults returns a copy of the policy with its zero fields set to
+// their defaults.
+func (policy RetryPolicy) withDefaults() RetryPolicy {
+	if policy.MaxAttempts == 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.InitialBackoff == 0 {
+		policy.InitialBackoff = time.Second
+	}
+	if policy.MaxBackoff == 0 {
+		policy.MaxBackoff = 30 * time.Second
+	}
+	if policy.Multiplier == 0 {
+		policy.Multiplier = 2
+	}
+	if policy.Jitter == 0 {
+		policy.Jitter = 0.2
+	}
+	if policy.RateLimitBackoff == 0 {
+		policy.RateLimitBackoff = 60 * time.Second
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = Status.IsRetryable
+	}
+	return policy
+}
+
+// delay returns the delay before the retry following the given attempt.
+func (policy *RetryPolicy) delay(attempt int, status Status) time.Duration {
+	backoff := float64(policy.InitialBackoff)
+	for i := 1; i < attempt && backoff < float64(policy.MaxBackoff); i++ {
+		backoff *= policy.Multiplier
+	}
+	if backoff > float64(policy.MaxBackoff) {
+		backoff = float64(policy.MaxBackoff)
+	}
+	if policy.Jitter > 0 {
+		backoff *= 1 + policy.Jitter*(2*rand.Float64()-1)
+	}
+	delay := time.Duration(backoff)
+	if int(status) == LA_E_RATE_LIMIT && delay < policy.RateLimitBackoff {
+		delay = policy.RateLimitBackoff
+	}
+	return delay
+}
+
+// nonIdempotentCalls are the calls that count twice when repeated after
+// the server applied them.
+var nonIdempotentCalls = map[string]bool{
+	"IncrementActivationMeterAttributeUses": true,
+	"DecrementActivationMeterAttributeUses": true,
+}
+
+// retry calls call until it succeeds, fails with a status that is not
+// retryable, runs out of attempts or ctx is done. Without a retry policy
+// call is called once.
+func (c *Client) retry(ctx context.Context, name string, call func() error) error {
+	retry := c.Config().Retry
+	if retry == nil {
+		return call()
+	}
+	policy := retry.withDefaults()
+	for attempt := 1; ; attempt++ {
+		err := call()
+		status := StatusOf(err)
+		if err == nil || !policy.Retryable(status) || attempt >= policy.MaxAttempts {
+			return err
+		}
+		if nonIdempotentCalls[name] && !status.IsSafeToResend() {
+			return err
+		}
+		delay := policy.delay(attempt, status)
+		if policy.OnRetry != nil {
+			policy.OnRetry(RetryAttempt{Call: name, Attempt: attempt, Err: err, Delay: delay})
+		}
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
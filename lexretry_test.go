@@ -0,0 +1,39 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package lexactivator_test
+
+import (
+	"testing"
+	"time"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+	"github.com/Exostellar/lexactivator-go/fake"
+)
+
+func TestRetryMeterAttributeUses(t *testing.T) {
+	tests := []struct {
+		status   int
+		attempts int
+	}{
+		{lexactivator.LA_E_RATE_LIMIT, 3},
+		{lexactivator.LA_E_INET, 1},
+		{lexactivator.LA_E_SERVER, 1},
+	}
+	for _, test := range tests {
+		b := fake.New()
+		client, err := lexactivator.New(lexactivator.Config{
+			Backend:     b,
+			ProductData: "data",
+			ProductId:   "id",
+			Retry:       &lexactivator.RetryPolicy{InitialBackoff: time.Millisecond, RateLimitBackoff: time.Millisecond},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		b.Fail("IncrementActivationMeterAttributeUses", test.status)
+		client.IncrementActivationMeterAttributeUses("exports", 1)
+		if calls := b.Calls("IncrementActivationMeterAttributeUses"); calls != test.attempts {
+			t.Errorf("%s: %d attempts, want %d", lexactivator.Status(test.status), calls, test.attempts)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package lexactivator
+
+import "errors"
+
+// Status is a typed LexActivator status code. The LA_* constants are plain
+// ints for compatibility, convert them with Status(code) to get a readable
+// name and classification:
+//
+//	status := lexactivator.Status(lexactivator.ActivateLicense())
+//	if status.IsRetryable() {
+//		...
+//	}
+type Status int
+
+// StatusCategory groups status codes by what a caller is expected to do
+// about them.
+type StatusCategory int
+
+const (
+	// The call succeeded.
+	CategoryOK StatusCategory = iota
+
+	// Generic or unknown failure.
+	CategoryFailure
+
+	// The license or trial is genuine but not active (expired, suspended,
+	// grace period over). The user usually needs to be informed.
+	CategoryLicenseState
+
+	// Result of a release update check.
+	CategoryRelease
+
+	// The server could not be reached or refused to serve the request.
+	CategoryNetwork
+
+	// The system clock is off or has been tampered with.
+	CategoryTimeTampering
+
+	// The machine, its location or its permissions are not allowed.
+	CategoryEnvironment
+
+	// The license, trial or meter attribute does not permit the operation.
+	CategoryLicense
+
+	// Invalid arguments or product configuration, usually a programming error.
+	CategoryConfiguration
+)
+
+var categoryNames = map[StatusCategory]string{
+	CategoryOK:            "ok",
+	CategoryFailure:       "failure",
+	CategoryLicenseState:  "license state",
+	CategoryRelease:       "release",
+	CategoryNetwork:       "network",
+	CategoryTimeTampering: "time tampering",
+	CategoryEnvironment:   "environment",
+	CategoryLicense:       "license",
+	CategoryConfiguration: "configuration",
+}
+
+func (c StatusCategory) String() string {
+	if name, ok := categoryNames[c]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// String returns the symbolic name of the status, e.g. "LA_E_INET".
+func (s Status) String() string {
+	return lookupStatus(int(s)).name
+}
+
+// Description returns the human readable message of the status.
+func (s Status) Description() string {
+	return lookupStatus(int(s)).message
+}
+
+// Err returns the status as an error, nil for LA_OK.
+func (s Status) Err() error {
+	return StatusToError(int(s))
+}
+
+// IsOK reports whether the status is LA_OK.
+func (s Status) IsOK() bool {
+	return int(s) == LA_OK
+}
+
+// IsInformational reports whether the status describes the state of a
+// genuine license, trial or release rather than a failure.
+func (s Status) IsInformational() bool {
+	return isInformationalStatus(int(s))
+}
+
+// Category returns the category the status belongs to.
+func (s Status) Category() StatusCategory {
+	switch int(s) {
+	case LA_OK:
+		return CategoryOK
+	case LA_EXPIRED, LA_SUSPENDED, LA_GRACE_PERIOD_OVER, LA_TRIAL_EXPIRED, LA_LOCAL_TRIAL_EXPIRED:
+		return CategoryLicenseState
+	case LA_RELEASE_UPDATE_AVAILABLE, LA_RELEASE_UPDATE_NOT_AVAILABLE, LA_RELEASE_UPDATE_AVAILABLE_NOT_ALLOWED,
+		LA_E_RELEASE_VERSION_NOT_ALLOWED:
+		return CategoryRelease
+	case LA_E_INET, LA_E_HOST_URL, LA_E_RATE_LIMIT, LA_E_SERVER, LA_E_CLIENT:
+		return CategoryNetwork
+	case LA_E_TIME, LA_E_TIME_MODIFIED:
+		return CategoryTimeTampering
+	case LA_E_SYSTEM_PERMISSION, LA_E_FILE_PERMISSION, LA_E_WMIC, LA_E_MACHINE_FINGERPRINT,
+		LA_E_VM, LA_E_CONTAINER, LA_E_COUNTRY, LA_E_IP:
+		return CategoryEnvironment
+	case LA_E_REVOKED, LA_E_LICENSE_KEY, LA_E_LICENSE_TYPE, LA_E_OFFLINE_RESPONSE_FILE,
+		LA_E_OFFLINE_RESPONSE_FILE_EXPIRED, LA_E_ACTIVATION_LIMIT, LA_E_ACTIVATION_NOT_FOUND,
+		LA_E_DEACTIVATION_LIMIT, LA_E_TRIAL_NOT_ALLOWED, LA_E_TRIAL_ACTIVATION_LIMIT,
+		LA_E_ACTIVATION_METADATA_LIMIT, LA_E_TRIAL_ACTIVATION_METADATA_LIMIT, LA_E_METADATA_KEY_NOT_FOUND,
+		LA_E_AUTHENTICATION_FAILED, LA_E_METER_ATTRIBUTE_NOT_FOUND, LA_E_METER_ATTRIBUTE_USES_LIMIT_REACHED,
+		LA_E_PRODUCT_VERSION_NOT_LINKED, LA_E_FEATURE_FLAG_NOT_FOUND:
+		return CategoryLicense
+	case LA_E_NET_PROXY, LA_E_FILE_PATH, LA_E_PRODUCT_FILE, LA_E_PRODUCT_DATA, LA_E_PRODUCT_ID, LA_E_BUFFER_SIZE,
+		LA_E_APP_VERSION_LENGTH, LA_E_METADATA_KEY_LENGTH, LA_E_METADATA_VALUE_LENGTH,
+		LA_E_RELEASE_VERSION_FORMAT, LA_E_CUSTOM_FINGERPRINT_LENGTH, LA_E_RELEASE_PLATFORM_LENGTH,
+		LA_E_RELEASE_CHANNEL_LENGTH, LA_E_RELEASE_VERSION, LA_E_RELEASE_PLATFORM, LA_E_RELEASE_CHANNEL:
+		return CategoryConfiguration
+	}
+	return CategoryFailure
+}
+
+// IsNetwork reports whether the status is caused by the communication with
+// the Cryptlex servers.
+func (s Status) IsNetwork() bool {
+	return s.Category() == CategoryNetwork
+}
+
+// IsTimeTampering reports whether the status indicates that the system time
+// is out of sync with the network time or has been backdated.
+func (s Status) IsTimeTampering() bool {
+	return s.Category() == CategoryTimeTampering
+}
+
+// IsLicenseState reports whether the license or trial is genuine but
+// expired, suspended or past its server sync grace period.
+func (s Status) IsLicenseState() bool {
+	return s.Category() == CategoryLicenseState
+}
+
+// IsRetryable reports whether the status is transient, so that repeating
+// the same call later may succeed.
+func (s Status) IsRetryable() bool {
+	switch int(s) {
+	case LA_E_INET, LA_E_RATE_LIMIT, LA_E_SERVER:
+		return true
+	}
+	return false
+}
+
+// IsSafeToResend reports whether the status means the request was not
+// applied by the server, so that even a call that is not idempotent, such
+// as IncrementActivationMeterAttributeUses(), can be repeated without being
+// counted twice. Only LA_E_RATE_LIMIT, the server turning the request
+// away, qualifies: LA_E_SERVER and LA_E_INET, e.g. on a timeout after the
+// request was sent, may be returned after the server applied it.
+func (s Status) IsSafeToResend() bool {
+	return int(s) == LA_E_RATE_LIMIT
+}
+
+// Status returns the typed status code of the error.
+func (e *StatusError) Status() Status {
+	return Status(e.Code)
+}
+
+// StatusOf returns the status carried by err: LA_OK for nil, the code of a
+// *StatusError and LA_FAIL for any other error.
+func StatusOf(err error) Status {
+	if err == nil {
+		return Status(LA_OK)
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Status()
+	}
+	return Status(LA_FAIL)
+}
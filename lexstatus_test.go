@@ -0,0 +1,26 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package lexactivator
+
+import "testing"
+
+func TestStatusResend(t *testing.T) {
+	tests := []struct {
+		status     int
+		retryable  bool
+		resendable bool
+		category   StatusCategory
+	}{
+		{LA_E_RATE_LIMIT, true, true, CategoryNetwork},
+		{LA_E_INET, true, false, CategoryNetwork},
+		{LA_E_SERVER, true, false, CategoryNetwork},
+		{LA_E_NET_PROXY, false, false, CategoryConfiguration},
+		{LA_E_METER_ATTRIBUTE_USES_LIMIT_REACHED, false, false, CategoryLicense},
+	}
+	for _, test := range tests {
+		status := Status(test.status)
+		if status.IsRetryable() != test.retryable || status.IsSafeToResend() != test.resendable || status.Category() != test.category {
+			t.Errorf("%s: retryable %v, safe to resend %v, category %s", status, status.IsRetryable(), status.IsSafeToResend(), status.Category())
+		}
+	}
+}
@@ -0,0 +1,330 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+//go:build !cgo || lexactivator_stub
+// +build !cgo lexactivator_stub
+
+package lexactivator
+
+import "sync/atomic"
+
+// This file is a pure Go stand-in for the native LexActivator library. It is
+// compiled instead of the cgo binding when cgo is disabled (CGO_ENABLED=0) or
+// when building with the lexactivator_stub tag:
+//
+//	go build -tags lexactivator_stub ./...
+//
+// so that the package builds, vets and tests on machines without the native
+// libraries. Every function returns the status set by SetStubStatus(),
+// LA_FAIL by default, and leaves its out parameters untouched, after the
+// argument checks the cgo binding does in Go. The release update callbacks
+// are invoked synchronously with LA_RELEASE_UPDATE_NOT_AVAILABLE and no
+// release when the status is LA_OK, so that callers waiting for them do not
+// hang; the license callback is never invoked.
+
+var stubStatus int32 = int32(LA_FAIL)
+
+// SetStubStatus sets the status returned by every function of the stub
+// build. It is only available in the stub build.
+func SetStubStatus(status int) {
+	atomic.StoreInt32(&stubStatus, int32(status))
+}
+
+func currentStubStatus() int {
+	return int(atomic.LoadInt32(&stubStatus))
+}
+
+func SetProductFile(filePath string) int {
+	return currentStubStatus()
+}
+
+func SetProductData(productData string) int {
+	return currentStubStatus()
+}
+
+func SetProductId(productId string, flags uint) int {
+	return currentStubStatus()
+}
+
+func SetDataDirectory(directoryPath string) int {
+	return currentStubStatus()
+}
+
+func SetCustomDeviceFingerprint(fingerprint string) int {
+	return currentStubStatus()
+}
+
+func SetLicenseKey(licenseKey string) int {
+	return currentStubStatus()
+}
+
+func SetLicenseUserCredential(email string, password string) int {
+	return currentStubStatus()
+}
+
+func SetLicenseCallback(callbackFunction func(int)) int {
+	return currentStubStatus()
+}
+
+func registerLicenseCallback() int {
+	return currentStubStatus()
+}
+
+func SetActivationLeaseDuration(leaseDuration uint) int {
+	return currentStubStatus()
+}
+
+func SetActivationMetadata(key string, value string) int {
+	return currentStubStatus()
+}
+
+func SetTrialActivationMetadata(key string, value string) int {
+	return currentStubStatus()
+}
+
+func SetAppVersion(appVersion string) int {
+	return currentStubStatus()
+}
+
+func SetReleaseVersion(releaseVersion string) int {
+	if _, err := ParseVersion(releaseVersion); err != nil {
+		return LA_E_RELEASE_VERSION_FORMAT
+	}
+	return currentStubStatus()
+}
+
+func SetReleasePublishedDate(releasePublishedDate uint) int {
+	return currentStubStatus()
+}
+
+func SetReleasePlatform(releasePlatform string) int {
+	return currentStubStatus()
+}
+
+func SetReleaseChannel(releaseChannel string) int {
+	return currentStubStatus()
+}
+
+func SetOfflineActivationRequestMeterAttributeUses(name string, uses uint) int {
+	return currentStubStatus()
+}
+
+func SetNetworkProxy(proxy string) int {
+	return currentStubStatus()
+}
+
+func SetCryptlexHost(host string) int {
+	return currentStubStatus()
+}
+
+func GetProductMetadata(key string, value *string) int {
+	return currentStubStatus()
+}
+
+func GetProductVersionName(name *string) int {
+	return currentStubStatus()
+}
+
+func GetProductVersionDisplayName(displayName *string) int {
+	return currentStubStatus()
+}
+
+func GetProductVersionFeatureFlag(name string, enabled *bool, data *string) int {
+	return currentStubStatus()
+}
+
+func GetLicenseMetadata(key string, value *string) int {
+	return currentStubStatus()
+}
+
+func GetLicenseMeterAttribute(name string, allowedUses *uint, totalUses *uint, grossUses *uint) int {
+	return currentStubStatus()
+}
+
+func GetLicenseKey(licenseKey *string) int {
+	return currentStubStatus()
+}
+
+func GetLicenseAllowedActivations(allowedActivations *uint) int {
+	return currentStubStatus()
+}
+
+func GetLicenseTotalActivations(totalActivations *uint) int {
+	return currentStubStatus()
+}
+
+func GetLicenseExpiryDate(expiryDate *uint) int {
+	return currentStubStatus()
+}
+
+func GetLicenseMaintenanceExpiryDate(maintenanceExpiryDate *uint) int {
+	return currentStubStatus()
+}
+
+func GetLicenseMaxAllowedReleaseVersion(maxAllowedReleaseVersion *string) int {
+	return currentStubStatus()
+}
+
+func GetLicenseUserEmail(email *string) int {
+	return currentStubStatus()
+}
+
+func GetLicenseUserName(name *string) int {
+	return currentStubStatus()
+}
+
+func GetLicenseUserCompany(company *string) int {
+	return currentStubStatus()
+}
+
+func GetLicenseUserMetadata(key string, value *string) int {
+	return currentStubStatus()
+}
+
+func GetLicenseOrganizationName(organizationName *string) int {
+	return currentStubStatus()
+}
+
+func GetLicenseOrganizationAddress(organizationAddress *OrganizationAddress) int {
+	return currentStubStatus()
+}
+
+func getLicenseOrganizationAddress(organizationAddress *OrganizationAddress) (int, error) {
+	status := currentStubStatus()
+	return status, StatusToError(status)
+}
+
+func GetLicenseType(licenseType *string) int {
+	return currentStubStatus()
+}
+
+func GetActivationMetadata(key string, value *string) int {
+	return currentStubStatus()
+}
+
+func GetActivationMode(initialMode *string, currentMode *string) int {
+	return currentStubStatus()
+}
+
+func GetActivationMeterAttributeUses(name string, uses *uint) int {
+	return currentStubStatus()
+}
+
+func GetServerSyncGracePeriodExpiryDate(expiryDate *uint) int {
+	return currentStubStatus()
+}
+
+func GetTrialActivationMetadata(key string, value *string) int {
+	return currentStubStatus()
+}
+
+func GetTrialExpiryDate(trialExpiryDate *uint) int {
+	return currentStubStatus()
+}
+
+func GetTrialId(trialId *string) int {
+	return currentStubStatus()
+}
+
+func GetLocalTrialExpiryDate(trialExpiryDate *uint) int {
+	return currentStubStatus()
+}
+
+func GetLibraryVersion(libraryVersion *string) int {
+	return currentStubStatus()
+}
+
+func CheckForReleaseUpdate(platform string, version string, channel string, callbackFunction func(int)) int {
+	status := currentStubStatus()
+	if status == LA_OK && callbackFunction != nil {
+		callbackFunction(LA_RELEASE_UPDATE_NOT_AVAILABLE)
+	}
+	return status
+}
+
+func CheckReleaseUpdate(releaseUpdateCallbackFunction func(int, *Release, interface{}), releaseFlags uint, userData interface{}) int {
+	status := currentStubStatus()
+	if status == LA_OK && releaseUpdateCallbackFunction != nil {
+		releaseUpdateCallbackFunction(LA_RELEASE_UPDATE_NOT_AVAILABLE, nil, userData)
+	}
+	return status
+}
+
+func checkReleaseUpdate(releaseFlags uint, callback releaseCheckCallback) int {
+	status := currentStubStatus()
+	if status == LA_OK && callback != nil {
+		callback(LA_RELEASE_UPDATE_NOT_AVAILABLE, nil, nil)
+	}
+	return status
+}
+
+func ActivateLicense() int {
+	return currentStubStatus()
+}
+
+func ActivateLicenseOffline(filePath string) int {
+	return currentStubStatus()
+}
+
+func GenerateOfflineActivationRequest(filePath string) int {
+	return currentStubStatus()
+}
+
+func DeactivateLicense() int {
+	return currentStubStatus()
+}
+
+func GenerateOfflineDeactivationRequest(filePath string) int {
+	return currentStubStatus()
+}
+
+func IsLicenseGenuine() int {
+	return currentStubStatus()
+}
+
+func IsLicenseValid() int {
+	return currentStubStatus()
+}
+
+func ActivateTrial() int {
+	return currentStubStatus()
+}
+
+func ActivateTrialOffline(filePath string) int {
+	return currentStubStatus()
+}
+
+func GenerateOfflineTrialActivationRequest(filePath string) int {
+	return currentStubStatus()
+}
+
+func IsTrialGenuine() int {
+	return currentStubStatus()
+}
+
+func ActivateLocalTrial(trialLength uint) int {
+	return currentStubStatus()
+}
+
+func IsLocalTrialGenuine() int {
+	return currentStubStatus()
+}
+
+func ExtendLocalTrial(trialExtensionLength uint) int {
+	return currentStubStatus()
+}
+
+func IncrementActivationMeterAttributeUses(name string, increment uint) int {
+	return currentStubStatus()
+}
+
+func DecrementActivationMeterAttributeUses(name string, decrement uint) int {
+	return currentStubStatus()
+}
+
+func ResetActivationMeterAttributeUses(name string) int {
+	return currentStubStatus()
+}
+
+func Reset() int {
+	return currentStubStatus()
+}
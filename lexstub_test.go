@@ -0,0 +1,44 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+//go:build !cgo || lexactivator_stub
+// +build !cgo lexactivator_stub
+
+package lexactivator
+
+import (
+	"context"
+	"testing"
+)
+
+func TestStubReleaseUpdate(t *testing.T) {
+	SetStubStatus(LA_OK)
+	defer SetStubStatus(LA_FAIL)
+
+	if status := CheckReleaseUpdate(nil, LA_RELEASES_ALL, nil); status != LA_OK {
+		t.Fatalf("CheckReleaseUpdate without a callback: %s", Status(status))
+	}
+	if status := CheckForReleaseUpdate("linux", "1.0.0", "stable", nil); status != LA_OK {
+		t.Fatalf("CheckForReleaseUpdate without a callback: %s", Status(status))
+	}
+	var got int
+	CheckForReleaseUpdate("linux", "1.0.0", "stable", func(status int) { got = status })
+	if got != LA_RELEASE_UPDATE_NOT_AVAILABLE {
+		t.Fatalf("CheckForReleaseUpdate callback status %s", Status(got))
+	}
+	result, err := CheckRelease(context.Background(), LA_RELEASES_ALL)
+	if err != nil || int(result.Status) != LA_RELEASE_UPDATE_NOT_AVAILABLE || result.Release != nil {
+		t.Fatalf("CheckRelease: %+v, %v", result, err)
+	}
+}
+
+func TestStubSetReleaseVersion(t *testing.T) {
+	SetStubStatus(LA_OK)
+	defer SetStubStatus(LA_FAIL)
+
+	if status := SetReleaseVersion("1.2.3"); status != LA_OK {
+		t.Fatalf("SetReleaseVersion(1.2.3): %s", Status(status))
+	}
+	if status := SetReleaseVersion("latest"); status != LA_E_RELEASE_VERSION_FORMAT {
+		t.Fatalf("SetReleaseVersion(latest): %s", Status(status))
+	}
+}
@@ -0,0 +1,174 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package lexactivator
+
+import (
+	"fmt"
+	"time"
+)
+
+// The functions in this file are time.Time and time.Duration based variants
+// of the functions dealing with raw unix timestamps, seconds and days.
+//
+// LexActivator reports a timestamp of 0 for dates that are not set, most
+// notably the expiry date of a license that never expires. The expiry
+// getters below return expires == false in that case instead of the unix
+// epoch, so a lifetime license is never mistaken for one expired in 1970.
+
+const day = 24 * time.Hour
+
+func unixTime(timestamp uint) (time.Time, bool) {
+	if timestamp == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(timestamp), 0), true
+}
+
+// LicenseExpiryTime returns the license expiry time. expires is false for
+// a license that never expires.
+func LicenseExpiryTime() (expiry time.Time, expires bool, err error) {
+	var expiryDate uint
+	err = StatusToError(GetLicenseExpiryDate(&expiryDate))
+	expiry, expires = unixTime(expiryDate)
+	return expiry, expires, err
+}
+
+// LicenseMaintenanceExpiryTime returns the license maintenance expiry time.
+// expires is false when the maintenance never expires.
+func LicenseMaintenanceExpiryTime() (expiry time.Time, expires bool, err error) {
+	var maintenanceExpiryDate uint
+	err = StatusToError(GetLicenseMaintenanceExpiryDate(&maintenanceExpiryDate))
+	expiry, expires = unixTime(maintenanceExpiryDate)
+	return expiry, expires, err
+}
+
+// ServerSyncGracePeriodExpiryTime returns the server sync grace period
+// expiry time. expires is false when no grace period expiry is set.
+func ServerSyncGracePeriodExpiryTime() (expiry time.Time, expires bool, err error) {
+	var expiryDate uint
+	err = StatusToError(GetServerSyncGracePeriodExpiryDate(&expiryDate))
+	expiry, expires = unixTime(expiryDate)
+	return expiry, expires, err
+}
+
+// TrialExpiryTime returns the trial expiry time. expires is false when no
+// trial expiry is set.
+func TrialExpiryTime() (expiry time.Time, expires bool, err error) {
+	var trialExpiryDate uint
+	err = StatusToError(GetTrialExpiryDate(&trialExpiryDate))
+	expiry, expires = unixTime(trialExpiryDate)
+	return expiry, expires, err
+}
+
+// LocalTrialExpiryTime returns the local trial expiry time. expires is
+// false when no local trial expiry is set.
+func LocalTrialExpiryTime() (expiry time.Time, expires bool, err error) {
+	var trialExpiryDate uint
+	err = StatusToError(GetLocalTrialExpiryDate(&trialExpiryDate))
+	expiry, expires = unixTime(trialExpiryDate)
+	return expiry, expires, err
+}
+
+// SetActivationLease sets the lease duration for the activation, see
+// SetActivationLeaseDuration(). The duration is rounded up to whole seconds.
+func SetActivationLease(leaseDuration time.Duration) error {
+	seconds, err := durationSeconds(leaseDuration)
+	if err != nil {
+		return err
+	}
+	return StatusToError(SetActivationLeaseDuration(seconds))
+}
+
+// SetReleasePublishedTime sets the release published date of your
+// application, see SetReleasePublishedDate().
+func SetReleasePublishedTime(publishedAt time.Time) error {
+	timestamp, err := unixTimestamp(publishedAt)
+	if err != nil {
+		return err
+	}
+	return StatusToError(SetReleasePublishedDate(timestamp))
+}
+
+// ActivateLocalTrialFor starts a local trial of the given length, see
+// ActivateLocalTrial(). The length is rounded up to whole days.
+func ActivateLocalTrialFor(trialLength time.Duration) error {
+	days, err := durationDays(trialLength)
+	if err != nil {
+		return err
+	}
+	return StatusToError(ActivateLocalTrial(days))
+}
+
+// ExtendLocalTrialBy extends the local trial by the given length, see
+// ExtendLocalTrial(). The length is rounded up to whole days.
+func ExtendLocalTrialBy(trialExtensionLength time.Duration) error {
+	days, err := durationDays(trialExtensionLength)
+	if err != nil {
+		return err
+	}
+	return StatusToError(ExtendLocalTrial(days))
+}
+
+func unixTimestamp(t time.Time) (uint, error) {
+	if t.Unix() <= 0 {
+		return 0, fmt.Errorf("lexactivator: invalid timestamp %v", t)
+	}
+	return uint(t.Unix()), nil
+}
+
+func durationSeconds(length time.Duration) (uint, error) {
+	if length <= 0 {
+		return 0, fmt.Errorf("lexactivator: invalid activation lease duration %v", length)
+	}
+	return uint((length + time.Second - 1) / time.Second), nil
+}
+
+func durationDays(length time.Duration) (uint, error) {
+	if length <= 0 {
+		return 0, fmt.Errorf("lexactivator: invalid trial length %v", length)
+	}
+	return uint((length + day - 1) / day), nil
+}
+
+// parseReleaseTime parses the ISO 8601 timestamps of releases and release
+// files. An empty timestamp is the zero time.
+func parseReleaseTime(timestamp string) (time.Time, error) {
+	if timestamp == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("lexactivator: invalid timestamp %q: %w", timestamp, err)
+	}
+	return t, nil
+}
+
+// PublishedTime returns the time the release was published, or the zero
+// time if it is not set.
+func (release *Release) PublishedTime() (time.Time, error) {
+	return parseReleaseTime(release.PublishedAt)
+}
+
+// CreatedTime returns the time the release was created, or the zero time
+// if it is not set.
+func (release *Release) CreatedTime() (time.Time, error) {
+	return parseReleaseTime(release.CreatedAt)
+}
+
+// UpdatedTime returns the time the release was last updated, or the zero
+// time if it is not set.
+func (release *Release) UpdatedTime() (time.Time, error) {
+	return parseReleaseTime(release.UpdatedAt)
+}
+
+// CreatedTime returns the time the release file was created, or the zero
+// time if it is not set.
+func (file *ReleaseFile) CreatedTime() (time.Time, error) {
+	return parseReleaseTime(file.CreatedAt)
+}
+
+// UpdatedTime returns the time the release file was last updated, or the
+// zero time if it is not set.
+func (file *ReleaseFile) UpdatedTime() (time.Time, error) {
+	return parseReleaseTime(file.UpdatedAt)
+}
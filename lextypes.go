@@ -1,5 +1,18 @@
 package lexactivator
 
+import "encoding/json"
+
+const (
+	LA_USER      uint = 1
+	LA_SYSTEM    uint = 2
+	LA_IN_MEMORY uint = 4
+)
+
+const (
+   LA_RELEASES_ALL     uint = 1
+   LA_RELEASES_ALLOWED uint = 2
+)
+
 type ReleaseFile struct {
 	Size       int    `json:"size"`
 	Downloads  int    `json:"downloads"`
@@ -29,6 +42,10 @@ type Release struct {
     ProductId   string    	  `json:"productId"`
     Platforms   []string  	  `json:"platforms"`
     Files       []ReleaseFile `json:"files"`
+
+    // Raw is the JSON the release was decoded from, which holds the
+    // fields not modelled by the struct.
+    Raw json.RawMessage `json:"-"`
 }
 
 type OrganizationAddress struct {
@@ -38,4 +55,31 @@ type OrganizationAddress struct {
 	State		 string `json:"state"`
 	Country 	 string `json:"country"`
 	PostalCode 	 string `json:"postalCode"`
+
+	// Raw is the JSON the address was decoded from, which holds the
+	// fields not modelled by the struct.
+	Raw json.RawMessage `json:"-"`
 }
+
+// MeterAttribute holds the uses of a license meter attribute.
+type MeterAttribute struct {
+	Name        string
+	AllowedUses uint
+	TotalUses   uint
+	GrossUses   uint
+}
+
+// FeatureFlag holds a product version feature flag.
+type FeatureFlag struct {
+	Name    string
+	Enabled bool
+	Data    string
+}
+
+// Mode is the mode of an activation as returned by GetActivationMode().
+type Mode string
+
+const (
+	ModeOnline  Mode = "online"
+	ModeOffline Mode = "offline"
+)
@@ -0,0 +1,195 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package lexactivator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a release version in one of the formats accepted by
+// SetReleaseVersion(): x.x, x.x.x or x.x.x.x. Missing components compare
+// as 0, so 1.2 and 1.2.0 are equal.
+type Version struct {
+	components [4]uint32
+	n          int
+}
+
+// ParseVersion parses a release version in one of the formats x.x, x.x.x or
+// x.x.x.x. The returned error matches ErrReleaseVersionFormat with
+// errors.Is().
+func ParseVersion(version string) (Version, error) {
+	parts := strings.Split(version, ".")
+	if len(parts) < 2 || len(parts) > 4 {
+		return Version{}, versionFormatError(version)
+	}
+	v := Version{n: len(parts)}
+	for i, part := range parts {
+		if part == "" || strings.TrimLeft(part, "0123456789") != "" {
+			return Version{}, versionFormatError(version)
+		}
+		component, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return Version{}, versionFormatError(version)
+		}
+		v.components[i] = uint32(component)
+	}
+	return v, nil
+}
+
+// MustParseVersion is ParseVersion() panicking on invalid versions, for
+// versions known at compile time.
+func MustParseVersion(version string) Version {
+	v, err := ParseVersion(version)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+func versionFormatError(version string) error {
+	return fmt.Errorf("lexactivator: invalid release version %q: %w", version, ErrReleaseVersionFormat)
+}
+
+// String returns the version with as many components as it was parsed
+// from.
+func (v Version) String() string {
+	parts := make([]string, v.n)
+	for i := range parts {
+		parts[i] = strconv.FormatUint(uint64(v.components[i]), 10)
+	}
+	return strings.Join(parts, ".")
+}
+
+// IsZero reports whether v is the zero Version, which is not a valid
+// release version.
+func (v Version) IsZero() bool {
+	return v.n == 0
+}
+
+// Compare returns -1, 0 or +1 depending on whether v is lower than, equal
+// to or higher than w.
+func (v Version) Compare(w Version) int {
+	for i := range v.components {
+		switch {
+		case v.components[i] < w.components[i]:
+			return -1
+		case v.components[i] > w.components[i]:
+			return 1
+		}
+	}
+	return 0
+}
+
+// CompareVersions parses and compares two release versions, see
+// Version.Compare().
+func CompareVersions(a, b string) (int, error) {
+	v, err := ParseVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	w, err := ParseVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	return v.Compare(w), nil
+}
+
+// ParsedVersion returns the parsed version of the release.
+func (release *Release) ParsedVersion() (Version, error) {
+	return ParseVersion(release.Version)
+}
+
+// IsNewerThan reports whether the release is newer than the given version,
+// e.g. the one set by SetReleaseVersion().
+func (release *Release) IsNewerThan(version string) (bool, error) {
+	c, err := CompareVersions(release.Version, version)
+	return c > 0, err
+}
+
+// IsAllowedByLicense reports whether the release is covered by a license
+// with the given max allowed release version, as returned by
+// GetLicenseMaxAllowedReleaseVersion(). An empty maxAllowedReleaseVersion
+// allows every release.
+func (release *Release) IsAllowedByLicense(maxAllowedReleaseVersion string) (bool, error) {
+	if maxAllowedReleaseVersion == "" {
+		return true, nil
+	}
+	c, err := CompareVersions(release.Version, maxAllowedReleaseVersion)
+	return c <= 0, err
+}
+
+// ReleaseEligibility explains whether a release is an update the license
+// allows.
+type ReleaseEligibility struct {
+	// Status is LA_RELEASE_UPDATE_AVAILABLE,
+	// LA_RELEASE_UPDATE_NOT_AVAILABLE or
+	// LA_RELEASE_UPDATE_AVAILABLE_NOT_ALLOWED.
+	Status Status
+
+	// Reason explains the status, e.g. "release 2.0.0 is newer than the
+	// max allowed release version 1.9 of the license".
+	Reason string
+}
+
+// Eligibility explains whether the release is an update of currentVersion
+// that a license with the given max allowed release version allows.
+func (release *Release) Eligibility(currentVersion, maxAllowedReleaseVersion string) (ReleaseEligibility, error) {
+	newer, err := release.IsNewerThan(currentVersion)
+	if err != nil {
+		return ReleaseEligibility{}, err
+	}
+	if !newer {
+		return ReleaseEligibility{
+			Status: Status(LA_RELEASE_UPDATE_NOT_AVAILABLE),
+			Reason: fmt.Sprintf("release %s is not newer than the current version %s", release.Version, currentVersion),
+		}, nil
+	}
+	allowed, err := release.IsAllowedByLicense(maxAllowedReleaseVersion)
+	if err != nil {
+		return ReleaseEligibility{}, err
+	}
+	if !allowed {
+		return ReleaseEligibility{
+			Status: Status(LA_RELEASE_UPDATE_AVAILABLE_NOT_ALLOWED),
+			Reason: fmt.Sprintf("release %s is newer than the max allowed release version %s of the license", release.Version, maxAllowedReleaseVersion),
+		}, nil
+	}
+	return ReleaseEligibility{
+		Status: Status(LA_RELEASE_UPDATE_AVAILABLE),
+		Reason: fmt.Sprintf("release %s is newer than the current version %s", release.Version, currentVersion),
+	}, nil
+}
+
+// LatestRelease returns the newest of the releases newer than
+// currentVersion that a license with the given max allowed release version
+// allows, or nil if there is none. Releases with invalid versions are
+// skipped.
+func LatestRelease(releases []Release, currentVersion, maxAllowedReleaseVersion string) (*Release, error) {
+	current, err := ParseVersion(currentVersion)
+	if err != nil {
+		return nil, err
+	}
+	var maxAllowed Version
+	if maxAllowedReleaseVersion != "" {
+		if maxAllowed, err = ParseVersion(maxAllowedReleaseVersion); err != nil {
+			return nil, err
+		}
+	}
+	var latest *Release
+	var latestVersion Version
+	for i := range releases {
+		version, err := releases[i].ParsedVersion()
+		if err != nil || version.Compare(current) <= 0 {
+			continue
+		}
+		if !maxAllowed.IsZero() && version.Compare(maxAllowed) > 0 {
+			continue
+		}
+		if latest == nil || version.Compare(latestVersion) > 0 {
+			latest, latestVersion = &releases[i], version
+		}
+	}
+	return latest, nil
+}
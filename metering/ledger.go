@@ -0,0 +1,494 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+// Package metering records meter attribute uses and reports them to
+// Cryptlex.
+//
+// A Ledger accumulates uses while the application runs disconnected and
+// applies them once it can: through the next offline activation request, or
+// through IncrementActivationMeterAttributeUses() when connectivity returns.
+// A Meter batches the uses of online applications and reports them in the
+// background, and a Quota checks uses against the license quota before the
+// work consuming them is done.
+package metering
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"sync"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+)
+
+// ErrClosed is returned when using a closed Ledger.
+var ErrClosed = errors.New("metering: ledger closed")
+
+// Journal operations.
+const (
+	opAdd     = "add"
+	opApply   = "apply"
+	opOffline = "offline"
+	opCommit  = "commit"
+	opAbort   = "abort"
+)
+
+// journalRecord is a line of the journal.
+//
+// Uses are recorded by "add" records. Applying them is journaled as a batch:
+// an "apply" record before calling IncrementActivationMeterAttributeUses()
+// for one attribute, or an "offline" record before generating an offline
+// activation request carrying the totals of all attributes, followed by a
+// "commit" or "abort" record for the batch once the outcome is known.
+type journalRecord struct {
+	Op    string `json:"op"`
+	Batch uint64 `json:"batch,omitempty"`
+	Name  string `json:"name,omitempty"`
+	Uses  uint   `json:"uses,omitempty"`
+
+	// Before is the activation meter attribute uses read before an
+	// increment, which tells whether an interrupted increment went through.
+	Before uint `json:"before,omitempty"`
+
+	Totals map[string]uint `json:"totals,omitempty"`
+}
+
+// Ledger is a persistent ledger of the meter attribute uses not yet
+// reported. Every change is appended to a journal file and synced to disk
+// before it takes effect, so that uses are neither lost nor counted twice
+// when the application crashes:
+//
+//   - an increment interrupted by a crash is resolved on the next Sync() by
+//     comparing the activation meter attribute uses with the ones read
+//     before the increment;
+//   - an offline activation request interrupted by a crash is considered
+//     not generated, since it could not have been handed over, and its uses
+//     go into the next one.
+//
+// Settling an increment assumes that the activation meter attribute uses
+// only change through the ledger: the meter attributes recorded by a Ledger
+// must not be incremented by anything else, another Ledger or a Meter
+// included, or an increment that did not go through may be taken for one
+// that did.
+//
+// A Ledger is safe for concurrent use. The journal must not be shared
+// between processes.
+type Ledger struct {
+	backend lexactivator.Backend
+	path    string
+
+	mu      sync.Mutex
+	file    *os.File
+	pending map[string]uint
+	applies map[uint64]journalRecord
+	batch   uint64
+}
+
+// Open opens the ledger journaled at path, creating it if needed. The
+// recorded uses are reported through backend, or through the native
+// library if backend is nil.
+func Open(path string, backend lexactivator.Backend) (*Ledger, error) {
+	if backend == nil {
+		backend = lexactivator.Native()
+	}
+	l := &Ledger{
+		backend: backend,
+		path:    path,
+		pending: map[string]uint{},
+		applies: map[uint64]journalRecord{},
+	}
+	if err := l.replay(); err != nil {
+		return nil, err
+	}
+	if err := l.compact(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Record records uses of the meter attribute name.
+func (l *Ledger) Record(name string, uses uint) error {
+	if uses == 0 {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.append(journalRecord{Op: opAdd, Name: name, Uses: uses}); err != nil {
+		return err
+	}
+	l.pending[name] += uses
+	return nil
+}
+
+// Pending returns the uses recorded and not yet reported, per attribute.
+func (l *Ledger) Pending() map[string]uint {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	pending := make(map[string]uint, len(l.pending))
+	for name, uses := range l.pending {
+		if uses > 0 {
+			pending[name] = uses
+		}
+	}
+	return pending
+}
+
+// Sync reports the pending uses with IncrementActivationMeterAttributeUses(),
+// one attribute at a time. An attribute whose uses limit is reached is
+// skipped, its uses are kept pending and LA_E_METER_ATTRIBUTE_USES_LIMIT_REACHED
+// is returned once the other attributes are reported. Sync stops at any
+// other failure, keeping the uses of that attribute and the following ones
+// pending.
+//
+// An increment failing with a status after which the server may have
+// applied it, such as LA_E_INET, is settled like an interrupted one by the
+// next Sync() or GenerateOfflineActivationRequest().
+func (l *Ledger) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return ErrClosed
+	}
+	if err := l.resolveApplies(); err != nil {
+		return err
+	}
+	var limitErr error
+	for _, name := range l.names() {
+		uses := l.pending[name]
+		if uses == 0 {
+			continue
+		}
+		before, err := l.activationUses(name)
+		if err != nil {
+			return err
+		}
+		l.batch++
+		apply := journalRecord{Op: opApply, Batch: l.batch, Name: name, Uses: uses, Before: before}
+		if err := l.append(apply); err != nil {
+			return err
+		}
+		l.applies[apply.Batch] = apply
+		status := l.backend.IncrementActivationMeterAttributeUses(name, uses)
+		switch {
+		case status == lexactivator.LA_OK:
+			if err := l.commit(apply.Batch); err != nil {
+				return err
+			}
+		case status == lexactivator.LA_E_METER_ATTRIBUTE_USES_LIMIT_REACHED:
+			if err := l.abort(apply.Batch); err != nil {
+				return err
+			}
+			if limitErr == nil {
+				limitErr = fmt.Errorf("metering: %s: %w", name, lexactivator.StatusToError(status))
+			}
+		case lexactivator.Status(status).IsSafeToResend():
+			if err := l.abort(apply.Batch); err != nil {
+				return err
+			}
+			return lexactivator.StatusToError(status)
+		default:
+			// The increment may have been applied, the apply record is
+			// left open for resolveApplies().
+			return lexactivator.StatusToError(status)
+		}
+	}
+	return limitErr
+}
+
+// SyncOnEvents calls Sync() whenever events report a successful server
+// sync, i.e. when connectivity returns, until ctx is done or events is
+// closed. The errors of Sync() are passed to onError, which may be nil.
+//
+//	events, err := client.LicenseEvents(ctx)
+//	if err != nil {
+//		...
+//	}
+//	go ledger.SyncOnEvents(ctx, events, nil)
+func (l *Ledger) SyncOnEvents(ctx context.Context, events <-chan lexactivator.LicenseEvent, onError func(error)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if int(event.Status) != lexactivator.LA_OK {
+				continue
+			}
+			if err := l.Sync(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// GenerateOfflineActivationRequest sets the pending uses of every attribute
+// with SetOfflineActivationRequestMeterAttributeUses() and generates the
+// offline activation request with GenerateOfflineActivationRequest(). The
+// uses are no longer pending once the request is generated.
+//
+// Attributes without pending uses are set to zero, so that uses carried by
+// an earlier request are not sent again.
+func (l *Ledger) GenerateOfflineActivationRequest(filePath string) error {
+	return lexactivator.StatusToError(l.generateOfflineActivationRequest(filePath))
+}
+
+func (l *Ledger) generateOfflineActivationRequest(filePath string) int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return lexactivator.LA_FAIL
+	}
+	// An interrupted increment must be settled first, its uses would be
+	// counted twice if it went through and the request carried them too.
+	if err := l.resolveApplies(); err != nil {
+		return int(lexactivator.StatusOf(err))
+	}
+	totals := map[string]uint{}
+	for _, name := range l.names() {
+		uses := l.pending[name]
+		if status := l.backend.SetOfflineActivationRequestMeterAttributeUses(name, uses); status != lexactivator.LA_OK {
+			return status
+		}
+		if uses > 0 {
+			totals[name] = uses
+		}
+	}
+	if len(totals) == 0 {
+		return l.backend.GenerateOfflineActivationRequest(filePath)
+	}
+	l.batch++
+	batch := l.batch
+	if err := l.append(journalRecord{Op: opOffline, Batch: batch, Totals: totals}); err != nil {
+		return lexactivator.LA_E_FILE_PERMISSION
+	}
+	status := l.backend.GenerateOfflineActivationRequest(filePath)
+	if status != lexactivator.LA_OK {
+		if err := l.append(journalRecord{Op: opAbort, Batch: batch}); err != nil {
+			return lexactivator.LA_E_FILE_PERMISSION
+		}
+		return status
+	}
+	if err := l.append(journalRecord{Op: opCommit, Batch: batch}); err != nil {
+		// Without its commit record the batch is replayed as not
+		// generated, so the request must not be handed over either.
+		os.Remove(filePath)
+		return lexactivator.LA_E_FILE_PERMISSION
+	}
+	for name, uses := range totals {
+		l.pending[name] -= uses
+	}
+	return status
+}
+
+// Backend returns the backend of the ledger with its offline activation
+// request generation going through the ledger, e.g. for offline.New().
+func (l *Ledger) Backend() lexactivator.Backend {
+	return ledgerBackend{Backend: l.backend, ledger: l}
+}
+
+type ledgerBackend struct {
+	lexactivator.Backend
+	ledger *Ledger
+}
+
+func (b ledgerBackend) GenerateOfflineActivationRequest(filePath string) int {
+	return b.ledger.generateOfflineActivationRequest(filePath)
+}
+
+// Close closes the journal.
+func (l *Ledger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return ErrClosed
+	}
+	err := l.file.Close()
+	l.file = nil
+	return err
+}
+
+// resolveApplies settles the increments interrupted by a crash or failed
+// with a status after which they may have been applied. An increment is
+// taken as applied if the activation meter attribute uses grew by its uses
+// since it was journaled, which only holds if the attribute is incremented
+// by the ledger alone. It must be called with l.mu held.
+func (l *Ledger) resolveApplies() error {
+	batches := make([]uint64, 0, len(l.applies))
+	for batch := range l.applies {
+		batches = append(batches, batch)
+	}
+	sort.Slice(batches, func(i, j int) bool { return batches[i] < batches[j] })
+	for _, batch := range batches {
+		apply := l.applies[batch]
+		uses, err := l.activationUses(apply.Name)
+		if err != nil {
+			return err
+		}
+		if uses >= apply.Before+apply.Uses {
+			err = l.commit(batch)
+		} else {
+			err = l.abort(batch)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// activationUses returns the activation meter attribute uses, zero for an
+// attribute never used by the activation.
+func (l *Ledger) activationUses(name string) (uint, error) {
+	var uses uint
+	status := l.backend.GetActivationMeterAttributeUses(name, &uses)
+	if status == lexactivator.LA_E_METER_ATTRIBUTE_NOT_FOUND {
+		return 0, nil
+	}
+	return uses, lexactivator.StatusToError(status)
+}
+
+// commit must be called with l.mu held.
+func (l *Ledger) commit(batch uint64) error {
+	if err := l.append(journalRecord{Op: opCommit, Batch: batch}); err != nil {
+		return err
+	}
+	apply := l.applies[batch]
+	l.pending[apply.Name] -= apply.Uses
+	delete(l.applies, batch)
+	return nil
+}
+
+// abort must be called with l.mu held.
+func (l *Ledger) abort(batch uint64) error {
+	if err := l.append(journalRecord{Op: opAbort, Batch: batch}); err != nil {
+		return err
+	}
+	delete(l.applies, batch)
+	return nil
+}
+
+// names returns the recorded attribute names, sorted. It must be called
+// with l.mu held.
+func (l *Ledger) names() []string {
+	names := make([]string, 0, len(l.pending))
+	for name := range l.pending {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// append writes record to the journal and syncs it to disk. It must be
+// called with l.mu held.
+func (l *Ledger) append(record journalRecord) error {
+	if l.file == nil {
+		return ErrClosed
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if _, err := l.file.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	return l.file.Sync()
+}
+
+// replay rebuilds the state from the journal. A truncated last line, left
+// by a crash while appending, is ignored.
+func (l *Ledger) replay() error {
+	data, err := ioutil.ReadFile(l.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	offline := map[uint64]map[string]uint{}
+	lines := bytes.Split(data, []byte("\n"))
+	for i, line := range lines {
+		if len(line) == 0 {
+			continue
+		}
+		var record journalRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			// Only the last line lacks its newline.
+			if i == len(lines)-1 {
+				break
+			}
+			return fmt.Errorf("metering: reading %s line %d: %w", l.path, i+1, err)
+		}
+		if record.Batch > l.batch {
+			l.batch = record.Batch
+		}
+		switch record.Op {
+		case opAdd:
+			l.pending[record.Name] += record.Uses
+		case opApply:
+			l.applies[record.Batch] = record
+		case opOffline:
+			offline[record.Batch] = record.Totals
+		case opCommit:
+			if apply, ok := l.applies[record.Batch]; ok {
+				l.pending[apply.Name] -= apply.Uses
+				delete(l.applies, record.Batch)
+			}
+			for name, uses := range offline[record.Batch] {
+				l.pending[name] -= uses
+			}
+			delete(offline, record.Batch)
+		case opAbort:
+			delete(l.applies, record.Batch)
+			delete(offline, record.Batch)
+		default:
+			return fmt.Errorf("metering: reading %s line %d: unknown operation %q", l.path, i+1, record.Op)
+		}
+	}
+	return nil
+}
+
+// compact rewrites the journal with the current state, replacing it
+// atomically, and opens it for appending.
+func (l *Ledger) compact() error {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, name := range l.names() {
+		if err := encoder.Encode(journalRecord{Op: opAdd, Name: name, Uses: l.pending[name]}); err != nil {
+			return err
+		}
+	}
+	batches := make([]uint64, 0, len(l.applies))
+	for batch := range l.applies {
+		batches = append(batches, batch)
+	}
+	sort.Slice(batches, func(i, j int) bool { return batches[i] < batches[j] })
+	for _, batch := range batches {
+		if err := encoder.Encode(l.applies[batch]); err != nil {
+			return err
+		}
+	}
+	tmp := l.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(tmp, os.O_RDWR, 0600)
+	if err != nil {
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	f.Close()
+	if err := os.Rename(tmp, l.path); err != nil {
+		return err
+	}
+	l.file, err = os.OpenFile(l.path, os.O_WRONLY|os.O_APPEND, 0600)
+	return err
+}
@@ -0,0 +1,273 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package metering
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+	"github.com/Exostellar/lexactivator-go/fake"
+)
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "metering-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// newBackend returns a fake backend with an activated license with the
+// meter attributes "exports" and "prints", of allowedUses each.
+func newBackend(t *testing.T, allowedUses uint) *fake.Backend {
+	t.Helper()
+	b := fake.New()
+	b.Update(func(s *fake.State) {
+		s.License.MeterAttributes["exports"] = lexactivator.MeterAttribute{AllowedUses: allowedUses}
+		s.License.MeterAttributes["prints"] = lexactivator.MeterAttribute{AllowedUses: allowedUses}
+	})
+	for _, status := range []int{
+		b.SetProductData("data"),
+		b.SetProductId("id", lexactivator.LA_USER),
+		b.SetLicenseKey("key"),
+		b.ActivateLicense(),
+	} {
+		if status != lexactivator.LA_OK {
+			t.Fatalf("setting up the backend: %s", lexactivator.Status(status))
+		}
+	}
+	return b
+}
+
+// writeJournal writes records to the journal at path, followed by tail.
+func writeJournal(t *testing.T, path string, tail string, records ...journalRecord) {
+	t.Helper()
+	var lines []string
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines = append(lines, string(line)+"\n")
+	}
+	if err := ioutil.WriteFile(path, []byte(strings.Join(lines, "")+tail), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func openLedger(t *testing.T, path string, backend lexactivator.Backend) *Ledger {
+	t.Helper()
+	ledger, err := Open(path, backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ledger.Close() })
+	return ledger
+}
+
+func expectPending(t *testing.T, ledger *Ledger, want map[string]uint) {
+	t.Helper()
+	if pending := ledger.Pending(); !reflect.DeepEqual(pending, want) {
+		t.Fatalf("pending %v, want %v", pending, want)
+	}
+}
+
+func activationUses(b *fake.Backend, name string) uint {
+	return b.State().ActivationMeterAttributeUses[name]
+}
+
+func TestLedgerSync(t *testing.T) {
+	path := filepath.Join(tempDir(t), "ledger")
+	b := newBackend(t, 0)
+	ledger := openLedger(t, path, b)
+	if err := ledger.Record("exports", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := ledger.Record("exports", 3); err != nil {
+		t.Fatal(err)
+	}
+	expectPending(t, ledger, map[string]uint{"exports": 5})
+	if err := ledger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	expectPending(t, ledger, map[string]uint{})
+	if uses := activationUses(b, "exports"); uses != 5 {
+		t.Fatalf("%d uses reported, want 5", uses)
+	}
+
+	ledger.Close()
+	ledger = openLedger(t, path, b)
+	expectPending(t, ledger, map[string]uint{})
+}
+
+func TestLedgerReplayTruncatedLine(t *testing.T) {
+	path := filepath.Join(tempDir(t), "ledger")
+	writeJournal(t, path, `{"op":"add","name":"exp`,
+		journalRecord{Op: opAdd, Name: "exports", Uses: 2})
+	ledger := openLedger(t, path, newBackend(t, 0))
+	expectPending(t, ledger, map[string]uint{"exports": 2})
+
+	// A corrupt line other than the last one is not left by a crash.
+	writeJournal(t, path, "",
+		journalRecord{Op: opAdd, Name: "exports", Uses: 2})
+	data, _ := ioutil.ReadFile(path)
+	if err := ioutil.WriteFile(path, append([]byte("{\n"), data...), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(path, newBackend(t, 0)); err == nil {
+		t.Fatal("opened a corrupt journal")
+	}
+}
+
+func TestLedgerOpenApply(t *testing.T) {
+	tests := []struct {
+		name    string
+		applied bool
+	}{
+		{"applied", true},
+		{"not applied", false},
+	}
+	for _, test := range tests {
+		path := filepath.Join(tempDir(t), "ledger")
+		b := newBackend(t, 0)
+		if test.applied {
+			b.IncrementActivationMeterAttributeUses("exports", 2)
+		}
+		writeJournal(t, path, "",
+			journalRecord{Op: opAdd, Name: "exports", Uses: 2},
+			journalRecord{Op: opApply, Batch: 1, Name: "exports", Uses: 2})
+		ledger := openLedger(t, path, b)
+		expectPending(t, ledger, map[string]uint{"exports": 2})
+		if err := ledger.Sync(); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		expectPending(t, ledger, map[string]uint{})
+		if uses := activationUses(b, "exports"); uses != 2 {
+			t.Fatalf("%s: %d uses reported, want 2", test.name, uses)
+		}
+	}
+}
+
+func TestLedgerSyncFailure(t *testing.T) {
+	path := filepath.Join(tempDir(t), "ledger")
+	b := newBackend(t, 0)
+	ledger := openLedger(t, path, b)
+	if err := ledger.Record("exports", 2); err != nil {
+		t.Fatal(err)
+	}
+
+	// A rate limited increment was not applied and is resent.
+	b.Fail("IncrementActivationMeterAttributeUses", lexactivator.LA_E_RATE_LIMIT)
+	if err := ledger.Sync(); !errors.Is(err, lexactivator.ErrRateLimit) {
+		t.Fatalf("error %v, want ErrRateLimit", err)
+	}
+	if len(ledger.applies) != 0 {
+		t.Fatalf("%d applies left open after LA_E_RATE_LIMIT", len(ledger.applies))
+	}
+
+	// An increment failing with LA_E_INET may have been applied, it is
+	// settled by the next Sync().
+	b.Fail("IncrementActivationMeterAttributeUses", lexactivator.LA_E_INET)
+	if err := ledger.Sync(); !errors.Is(err, lexactivator.ErrInet) {
+		t.Fatalf("error %v, want ErrInet", err)
+	}
+	if len(ledger.applies) != 1 {
+		t.Fatalf("%d applies left open after LA_E_INET, want 1", len(ledger.applies))
+	}
+	b.Recover()
+	b.Update(func(s *fake.State) { s.ActivationMeterAttributeUses["exports"] = 2 })
+	if err := ledger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	expectPending(t, ledger, map[string]uint{})
+	if uses := activationUses(b, "exports"); uses != 2 {
+		t.Fatalf("%d uses reported, want 2", uses)
+	}
+}
+
+func TestLedgerSyncUsesLimitReached(t *testing.T) {
+	b := newBackend(t, 5)
+	ledger := openLedger(t, filepath.Join(tempDir(t), "ledger"), b)
+	if err := ledger.Record("exports", 6); err != nil {
+		t.Fatal(err)
+	}
+	if err := ledger.Record("prints", 3); err != nil {
+		t.Fatal(err)
+	}
+	if err := ledger.Sync(); !errors.Is(err, lexactivator.ErrMeterAttributeUsesLimitReached) {
+		t.Fatalf("error %v, want ErrMeterAttributeUsesLimitReached", err)
+	}
+	expectPending(t, ledger, map[string]uint{"exports": 6})
+	if uses := activationUses(b, "prints"); uses != 3 {
+		t.Fatalf("%d prints reported, want 3", uses)
+	}
+}
+
+func TestLedgerOfflineBatch(t *testing.T) {
+	dir := tempDir(t)
+	path := filepath.Join(dir, "ledger")
+	b := newBackend(t, 0)
+	ledger := openLedger(t, path, b)
+	if err := ledger.Record("exports", 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := ledger.GenerateOfflineActivationRequest(filepath.Join(dir, "request")); err != nil {
+		t.Fatal(err)
+	}
+	expectPending(t, ledger, map[string]uint{})
+	if uses := b.State().OfflineRequestMeterAttributeUses["exports"]; uses != 2 {
+		t.Fatalf("request carries %d uses, want 2", uses)
+	}
+
+	// A request generated without its commit record, as when crashing
+	// right after generating it, is replayed as not generated.
+	writeJournal(t, path, "",
+		journalRecord{Op: opAdd, Name: "exports", Uses: 2},
+		journalRecord{Op: opOffline, Batch: 1, Totals: map[string]uint{"exports": 2}})
+	ledger.Close()
+	ledger = openLedger(t, path, b)
+	expectPending(t, ledger, map[string]uint{"exports": 2})
+}
+
+// closingBackend closes the journal of ledger once the offline activation
+// request is generated, failing its commit record.
+type closingBackend struct {
+	*fake.Backend
+	ledger *Ledger
+}
+
+func (b closingBackend) GenerateOfflineActivationRequest(filePath string) int {
+	status := b.Backend.GenerateOfflineActivationRequest(filePath)
+	b.ledger.file.Close()
+	return status
+}
+
+func TestLedgerOfflineCommitFailure(t *testing.T) {
+	dir := tempDir(t)
+	b := closingBackend{Backend: newBackend(t, 0)}
+	ledger, err := Open(filepath.Join(dir, "ledger"), b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.ledger = ledger
+	ledger.backend = b
+	if err := ledger.Record("exports", 2); err != nil {
+		t.Fatal(err)
+	}
+	request := filepath.Join(dir, "request")
+	if err := ledger.GenerateOfflineActivationRequest(request); !errors.Is(err, lexactivator.ErrFilePermission) {
+		t.Fatalf("error %v, want ErrFilePermission", err)
+	}
+	if _, err := os.Stat(request); !os.IsNotExist(err) {
+		t.Fatalf("request left behind: %v", err)
+	}
+	expectPending(t, ledger, map[string]uint{"exports": 2})
+}
@@ -0,0 +1,298 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package metering
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+)
+
+// ErrMeterClosed is returned when using a closed Meter.
+var ErrMeterClosed = errors.New("metering: meter closed")
+
+// MeterConfig configures a Meter.
+type MeterConfig struct {
+	// Interval is the interval between flushes. Defaults to 10 seconds.
+	Interval time.Duration
+
+	// Threshold triggers a flush as soon as the pending uses of an
+	// attribute reach it. Zero only flushes on Interval.
+	Threshold uint
+
+	// OnError is called with the errors of the background flushes. If nil,
+	// they are ignored, and the uses they dropped go unnoticed.
+	OnError func(err *FlushError)
+}
+
+// FlushError is the error of reporting the uses of an attribute.
+type FlushError struct {
+	Name string
+
+	// Delta is the net uses that could not be reported, negative for a
+	// decrement.
+	Delta int64
+
+	// Dropped is true when the uses were dropped, because the failure is not
+	// transient or because the server may have counted them (LA_E_INET,
+	// LA_E_SERVER). Otherwise they are retried by the next flush.
+	Dropped bool
+
+	Err error
+}
+
+func (e *FlushError) Error() string {
+	verb := "will be retried"
+	if e.Dropped {
+		verb = "dropped"
+	}
+	return fmt.Sprintf("metering: reporting %+d uses of %s (%s): %v", e.Delta, e.Name, verb, e.Err)
+}
+
+func (e *FlushError) Unwrap() error {
+	return e.Err
+}
+
+// FlushErrors holds the errors of a flush: a *FlushError per attribute
+// whose uses could not be reported, and ctx.Err() if the flush was
+// interrupted. errors.Is() and errors.As() match any of them.
+type FlushErrors []error
+
+func (e FlushErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Is reports whether any of the errors matches target.
+func (e FlushErrors) Is(target error) bool {
+	for _, err := range e {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first of the errors that matches target.
+func (e FlushErrors) As(target interface{}) bool {
+	for _, err := range e {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Meter reports meter attribute uses in the background, off the hot path
+// of the application. Increments and decrements are coalesced per
+// attribute in memory and flushed every Interval, or as soon as an
+// attribute reaches Threshold, with IncrementActivationMeterAttributeUses()
+// or DecrementActivationMeterAttributeUses() of the client, which retries
+// them according to its RetryPolicy. Uses failing with a status safe to
+// resend, see Status.IsSafeToResend(), are kept for the next flush; other
+// failures, including LA_E_INET and LA_E_SERVER after which the server may
+// have counted them, drop them and are reported as a *FlushError.
+//
+//	meter := metering.NewMeter(client, metering.MeterConfig{Threshold: 100})
+//	defer meter.Close(ctx)
+//	...
+//	meter.Increment("api-calls", 1)
+//
+// Pending uses are lost if the program exits without Close() or Flush(); use
+// a Ledger to keep them across restarts. A Meter is safe for concurrent
+// use.
+type Meter struct {
+	client *lexactivator.Client
+	config MeterConfig
+
+	mu     sync.Mutex
+	deltas map[string]int64
+	closed bool
+
+	// flushMu serializes the flushes.
+	flushMu sync.Mutex
+
+	trigger chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewMeter returns a meter reporting through client and starts its
+// background flushes.
+func NewMeter(client *lexactivator.Client, config MeterConfig) *Meter {
+	if config.Interval <= 0 {
+		config.Interval = 10 * time.Second
+	}
+	m := &Meter{
+		client:  client,
+		config:  config,
+		deltas:  map[string]int64{},
+		trigger: make(chan struct{}, 1),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go m.run()
+	return m
+}
+
+// Increment records uses of the meter attribute name.
+func (m *Meter) Increment(name string, uses uint) error {
+	return m.add(name, int64(uses))
+}
+
+// Decrement records uses of the meter attribute name given back.
+func (m *Meter) Decrement(name string, uses uint) error {
+	return m.add(name, -int64(uses))
+}
+
+// Pending returns the net uses not yet reported, per attribute.
+func (m *Meter) Pending() map[string]int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pending := make(map[string]int64, len(m.deltas))
+	for name, delta := range m.deltas {
+		pending[name] = delta
+	}
+	return pending
+}
+
+func (m *Meter) add(name string, delta int64) error {
+	if delta == 0 {
+		return nil
+	}
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return ErrMeterClosed
+	}
+	m.deltas[name] += delta
+	total := m.deltas[name]
+	if total == 0 {
+		delete(m.deltas, name)
+	}
+	m.mu.Unlock()
+	if m.config.Threshold > 0 && (total >= int64(m.config.Threshold) || -total >= int64(m.config.Threshold)) {
+		select {
+		case m.trigger <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// Flush reports the pending uses, waiting for a background flush in
+// progress first. After trying every attribute, it returns FlushErrors
+// holding a *FlushError for each attribute that failed.
+//
+// The uses of an attribute being reported are never abandoned half way, so
+// that they cannot be counted twice: once ctx is done, Flush stops after
+// the call in progress, keeps the remaining uses pending and adds ctx.Err()
+// to the errors.
+func (m *Meter) Flush(ctx context.Context) error {
+	m.flushMu.Lock()
+	defer m.flushMu.Unlock()
+	if errs := m.flush(ctx); len(errs) > 0 {
+		return FlushErrors(errs)
+	}
+	return nil
+}
+
+// Close stops the background flushes and flushes the pending uses, see
+// Flush(). The meter records no more uses afterwards.
+func (m *Meter) Close(ctx context.Context) error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return ErrMeterClosed
+	}
+	m.closed = true
+	m.mu.Unlock()
+	close(m.stop)
+	<-m.done
+	return m.Flush(ctx)
+}
+
+func (m *Meter) run() {
+	defer close(m.done)
+	ticker := time.NewTicker(m.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+		case <-m.trigger:
+		}
+		m.flushMu.Lock()
+		errs := m.flush(context.Background())
+		m.flushMu.Unlock()
+		if m.config.OnError != nil {
+			for _, err := range errs {
+				if flushErr, ok := err.(*FlushError); ok {
+					m.config.OnError(flushErr)
+				}
+			}
+		}
+	}
+}
+
+// flush reports the pending uses. It must be called with m.flushMu held.
+func (m *Meter) flush(ctx context.Context) []error {
+	m.mu.Lock()
+	deltas := m.deltas
+	m.deltas = map[string]int64{}
+	m.mu.Unlock()
+
+	names := make([]string, 0, len(deltas))
+	for name := range deltas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for i, name := range names {
+		if err := ctx.Err(); err != nil {
+			m.restore(deltas, names[i:])
+			return append(errs, err)
+		}
+		delta := deltas[name]
+		var err error
+		if delta > 0 {
+			err = m.client.IncrementActivationMeterAttributeUses(name, uint(delta))
+		} else {
+			err = m.client.DecrementActivationMeterAttributeUses(name, uint(-delta))
+		}
+		if err == nil {
+			continue
+		}
+		flushErr := &FlushError{Name: name, Delta: delta, Err: err}
+		if lexactivator.StatusOf(err).IsSafeToResend() {
+			m.restore(deltas, names[i:i+1])
+		} else {
+			flushErr.Dropped = true
+		}
+		errs = append(errs, flushErr)
+	}
+	return errs
+}
+
+// restore puts back the deltas of names that could not be reported.
+func (m *Meter) restore(deltas map[string]int64, names []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, name := range names {
+		m.deltas[name] += deltas[name]
+		if m.deltas[name] == 0 {
+			delete(m.deltas, name)
+		}
+	}
+}
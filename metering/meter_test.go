@@ -0,0 +1,134 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package metering
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+	"github.com/Exostellar/lexactivator-go/fake"
+)
+
+// newMeter returns a meter reporting to b, closed at the end of the test.
+func newMeter(t *testing.T, b *fake.Backend, config MeterConfig) *Meter {
+	t.Helper()
+	client, err := lexactivator.New(lexactivator.Config{Backend: b, ProductData: "data", ProductId: "id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	meter := NewMeter(client, config)
+	t.Cleanup(func() { meter.Close(context.Background()) })
+	return meter
+}
+
+func TestMeterCoalesce(t *testing.T) {
+	b := newBackend(t, 0)
+	meter := newMeter(t, b, MeterConfig{Interval: time.Hour})
+	meter.Increment("exports", 2)
+	meter.Increment("exports", 3)
+	meter.Decrement("exports", 1)
+	meter.Increment("prints", 1)
+	meter.Decrement("prints", 1)
+	if pending, want := meter.Pending(), map[string]int64{"exports": 4}; !reflect.DeepEqual(pending, want) {
+		t.Fatalf("pending %v, want %v", pending, want)
+	}
+	if err := meter.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if calls := b.Calls("IncrementActivationMeterAttributeUses"); calls != 1 {
+		t.Fatalf("%d increments, want 1", calls)
+	}
+	if uses := activationUses(b, "exports"); uses != 4 {
+		t.Fatalf("%d uses reported, want 4", uses)
+	}
+	if pending := meter.Pending(); len(pending) != 0 {
+		t.Fatalf("pending %v after Flush()", pending)
+	}
+}
+
+func TestMeterThreshold(t *testing.T) {
+	b := newBackend(t, 0)
+	meter := newMeter(t, b, MeterConfig{Interval: time.Hour, Threshold: 5})
+	meter.Increment("exports", 4)
+	meter.Increment("exports", 1)
+	for deadline := time.Now().Add(5 * time.Second); activationUses(b, "exports") != 5; {
+		if time.Now().After(deadline) {
+			t.Fatal("reaching the threshold did not flush")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMeterFlushFailure(t *testing.T) {
+	tests := []struct {
+		status  int
+		dropped bool
+	}{
+		{lexactivator.LA_E_RATE_LIMIT, false},
+		{lexactivator.LA_E_INET, true},
+		{lexactivator.LA_E_SERVER, true},
+	}
+	for _, test := range tests {
+		b := newBackend(t, 0)
+		meter := newMeter(t, b, MeterConfig{Interval: time.Hour})
+		meter.Increment("exports", 2)
+		meter.Increment("prints", 3)
+		b.Fail("IncrementActivationMeterAttributeUses", test.status)
+
+		err := meter.Flush(context.Background())
+		var flushErrs FlushErrors
+		if !errors.As(err, &flushErrs) || len(flushErrs) != 2 {
+			t.Fatalf("%s: error %v, want a FlushErrors of both attributes", lexactivator.Status(test.status), err)
+		}
+		if !errors.Is(err, lexactivator.StatusToError(test.status)) {
+			t.Errorf("%s: error %v does not match the status", lexactivator.Status(test.status), err)
+		}
+		var flushErr *FlushError
+		if !errors.As(err, &flushErr) || flushErr.Name != "exports" || flushErr.Delta != 2 || flushErr.Dropped != test.dropped {
+			t.Errorf("%s: first error %+v", lexactivator.Status(test.status), flushErr)
+		}
+		want := map[string]int64{"exports": 2, "prints": 3}
+		if test.dropped {
+			want = map[string]int64{}
+		}
+		if pending := meter.Pending(); !reflect.DeepEqual(pending, want) {
+			t.Errorf("%s: pending %v, want %v", lexactivator.Status(test.status), pending, want)
+		}
+	}
+}
+
+func TestMeterFlushCanceled(t *testing.T) {
+	b := newBackend(t, 0)
+	meter := newMeter(t, b, MeterConfig{Interval: time.Hour})
+	meter.Increment("exports", 2)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := meter.Flush(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("error %v, want context.Canceled", err)
+	}
+	if pending, want := meter.Pending(), map[string]int64{"exports": 2}; !reflect.DeepEqual(pending, want) {
+		t.Fatalf("pending %v, want %v", pending, want)
+	}
+}
+
+func TestMeterClose(t *testing.T) {
+	b := newBackend(t, 0)
+	meter := newMeter(t, b, MeterConfig{Interval: time.Hour})
+	meter.Increment("exports", 2)
+	if err := meter.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if uses := activationUses(b, "exports"); uses != 2 {
+		t.Fatalf("%d uses reported on Close(), want 2", uses)
+	}
+	if err := meter.Increment("exports", 1); err != ErrMeterClosed {
+		t.Fatalf("error %v, want ErrMeterClosed", err)
+	}
+	if err := meter.Close(context.Background()); err != ErrMeterClosed {
+		t.Fatalf("error %v, want ErrMeterClosed", err)
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package metering
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+)
+
+var (
+	// ErrReservationDone is returned when committing a reservation that
+	// was already committed or released.
+	ErrReservationDone = errors.New("metering: reservation already committed or released")
+
+	// ErrNoUses is returned when reserving zero uses.
+	ErrNoUses = errors.New("metering: no uses to reserve")
+)
+
+// QuotaExceededError is returned by Reserve() when the uses do not fit in
+// the remaining quota. It matches lexactivator.ErrMeterAttributeUsesLimitReached
+// with errors.Is(), like the error the server returns once the limit is
+// reached.
+type QuotaExceededError struct {
+	Name      string
+	Requested uint
+	Remaining uint
+}
+
+func (e *QuotaExceededError) Error() string {
+	return fmt.Sprintf("metering: quota of %s exceeded: %d uses requested, %d remaining", e.Name, e.Requested, e.Remaining)
+}
+
+func (e *QuotaExceededError) Is(target error) bool {
+	return errors.Is(lexactivator.ErrMeterAttributeUsesLimitReached, target)
+}
+
+// Usage is the usage of a meter attribute as seen by a Quota.
+type Usage struct {
+	// Allowed, Total and Gross are the license uses returned by
+	// GetLicenseMeterAttribute(). Zero allowed uses means unlimited.
+	Allowed uint
+	Total   uint
+	Gross   uint
+
+	// Committed is the uses committed through the Quota that Total does
+	// not count yet.
+	Committed uint
+
+	// Reserved is the uses held by the pending reservations.
+	Reserved uint
+
+	// Remaining is the uses left for new reservations. It is meaningless
+	// when Unlimited is true.
+	Remaining uint
+	Unlimited bool
+}
+
+// Quota checks meter attribute uses against the license quota before
+// billable work is done:
+//
+//	reservation, err := quota.Reserve("exports", 1)
+//	if errors.Is(err, lexactivator.ErrMeterAttributeUsesLimitReached) {
+//		...
+//	}
+//	if err := export(); err != nil {
+//		reservation.Release()
+//		return err
+//	}
+//	return reservation.Commit()
+//
+// The pending reservations are held against the other callers of the
+// process, so that concurrent work cannot overrun the quota between the
+// check and the increment.
+//
+// The license uses read from the library are the ones of its last server
+// sync, so the uses committed since are counted by the Quota until the
+// total uses of the license change. Other activations of the license are
+// only seen once synced. A Quota is safe for concurrent use.
+type Quota struct {
+	client *lexactivator.Client
+
+	mu        sync.Mutex
+	reserved  map[string]uint
+	committed map[string]committedUses
+}
+
+// committedUses are the uses committed while the license total uses read
+// from the library were total.
+type committedUses struct {
+	total uint
+	uses  uint
+}
+
+// NewQuota returns a quota reading and incrementing the uses through
+// client.
+func NewQuota(client *lexactivator.Client) *Quota {
+	return &Quota{client: client, reserved: map[string]uint{}, committed: map[string]committedUses{}}
+}
+
+// Usage returns the usage of the meter attribute name.
+func (q *Quota) Usage(name string) (Usage, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.usage(name)
+}
+
+// usage must be called with q.mu held.
+func (q *Quota) usage(name string) (Usage, error) {
+	attribute, err := q.client.LicenseMeterAttribute(name)
+	if err != nil {
+		return Usage{}, err
+	}
+	// The total uses change once the library syncs the license, which
+	// counts the uses committed before.
+	committed, ok := q.committed[name]
+	if ok && committed.total != attribute.TotalUses {
+		delete(q.committed, name)
+		committed = committedUses{}
+	}
+	usage := Usage{
+		Allowed:   attribute.AllowedUses,
+		Total:     attribute.TotalUses,
+		Gross:     attribute.GrossUses,
+		Committed: committed.uses,
+		Reserved:  q.reserved[name],
+		Unlimited: attribute.AllowedUses == 0,
+	}
+	if used := usage.Total + usage.Committed + usage.Reserved; !usage.Unlimited && usage.Allowed > used {
+		usage.Remaining = usage.Allowed - used
+	}
+	return usage, nil
+}
+
+// Reserve holds uses of the meter attribute name if they fit in the
+// remaining quota, and returns a *QuotaExceededError otherwise. The
+// reservation must be committed or released.
+func (q *Quota) Reserve(name string, uses uint) (*Reservation, error) {
+	if uses == 0 {
+		return nil, ErrNoUses
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	usage, err := q.usage(name)
+	if err != nil {
+		return nil, err
+	}
+	if !usage.Unlimited && uses > usage.Remaining {
+		return nil, &QuotaExceededError{Name: name, Requested: uses, Remaining: usage.Remaining}
+	}
+	q.reserved[name] += uses
+	return &Reservation{quota: q, name: name, uses: uses}, nil
+}
+
+// Do reserves uses of the meter attribute name, runs work and commits the
+// reservation if work succeeds, or releases it otherwise. work is not run
+// when the uses do not fit in the quota.
+func (q *Quota) Do(name string, uses uint, work func() error) error {
+	reservation, err := q.Reserve(name, uses)
+	if err != nil {
+		return err
+	}
+	if err := work(); err != nil {
+		reservation.Release()
+		return err
+	}
+	return reservation.Commit()
+}
+
+// totalUses returns the license total uses of the meter attribute name.
+func (q *Quota) totalUses(name string) (uint, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	attribute, err := q.client.LicenseMeterAttribute(name)
+	return attribute.TotalUses, err
+}
+
+// commit counts uses committed while the license total uses were total.
+func (q *Quota) commit(name string, uses uint, total uint) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	committed := q.committed[name]
+	if committed.total != total {
+		committed = committedUses{total: total}
+	}
+	committed.uses += uses
+	q.committed[name] = committed
+}
+
+func (q *Quota) release(name string, uses uint) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.reserved[name] -= uses
+	if q.reserved[name] == 0 {
+		delete(q.reserved, name)
+	}
+}
+
+// Reservation is a hold on meter attribute uses returned by
+// Quota.Reserve().
+type Reservation struct {
+	quota *Quota
+	name  string
+	uses  uint
+
+	mu   sync.Mutex
+	done bool
+}
+
+// Name returns the name of the reserved meter attribute.
+func (r *Reservation) Name() string {
+	return r.name
+}
+
+// Uses returns the reserved uses.
+func (r *Reservation) Uses() uint {
+	return r.uses
+}
+
+// Commit reports the reserved uses with
+// IncrementActivationMeterAttributeUses() and releases the hold, whether the
+// increment succeeds or not. Once reported, the uses are counted by the
+// Quota until the library syncs the license.
+func (r *Reservation) Commit() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.done {
+		return ErrReservationDone
+	}
+	r.done = true
+	defer r.quota.release(r.name, r.uses)
+	total, err := r.quota.totalUses(r.name)
+	if err != nil {
+		return err
+	}
+	if err := r.quota.client.IncrementActivationMeterAttributeUses(r.name, r.uses); err != nil {
+		return err
+	}
+	r.quota.commit(r.name, r.uses, total)
+	return nil
+}
+
+// Release gives the reserved uses back. It does nothing once the
+// reservation is committed or released.
+func (r *Reservation) Release() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.done {
+		return
+	}
+	r.done = true
+	r.quota.release(r.name, r.uses)
+}
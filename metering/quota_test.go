@@ -0,0 +1,147 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package metering
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+	"github.com/Exostellar/lexactivator-go/fake"
+)
+
+func newQuota(t *testing.T, b lexactivator.Backend) *Quota {
+	t.Helper()
+	client, err := lexactivator.New(lexactivator.Config{Backend: b, ProductData: "data", ProductId: "id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewQuota(client)
+}
+
+// setTotalUses sets the license total uses of the meter attribute name read
+// from the library, as a server sync does.
+func setTotalUses(b *fake.Backend, name string, uses uint) {
+	b.Update(func(s *fake.State) {
+		attribute := s.License.MeterAttributes[name]
+		attribute.TotalUses = uses
+		s.License.MeterAttributes[name] = attribute
+	})
+}
+
+// staleBackend reports the license total uses of the last server sync, zero
+// in the tests, as the library does until it syncs the license again.
+type staleBackend struct {
+	*fake.Backend
+}
+
+func (b staleBackend) GetLicenseMeterAttribute(name string, allowedUses *uint, totalUses *uint, grossUses *uint) int {
+	status := b.Backend.GetLicenseMeterAttribute(name, allowedUses, totalUses, grossUses)
+	*totalUses, *grossUses = 0, 0
+	return status
+}
+
+func TestQuotaReserve(t *testing.T) {
+	b := newBackend(t, 10)
+	quota := newQuota(t, b)
+	reservation, err := quota.Reserve("exports", 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage, err := quota.Usage("exports"); err != nil || usage.Reserved != 4 || usage.Remaining != 6 {
+		t.Fatalf("usage %+v, %v", usage, err)
+	}
+	_, err = quota.Reserve("exports", 7)
+	var exceeded *QuotaExceededError
+	if !errors.As(err, &exceeded) || exceeded.Remaining != 6 || !errors.Is(err, lexactivator.ErrMeterAttributeUsesLimitReached) {
+		t.Fatalf("error %v, want a *QuotaExceededError", err)
+	}
+	reservation.Release()
+	if usage, err := quota.Usage("exports"); err != nil || usage.Reserved != 0 || usage.Remaining != 10 {
+		t.Fatalf("usage %+v after Release(), %v", usage, err)
+	}
+	if err := reservation.Commit(); err != ErrReservationDone {
+		t.Fatalf("error %v, want ErrReservationDone", err)
+	}
+	if _, err := quota.Reserve("exports", 0); err != ErrNoUses {
+		t.Fatalf("error %v, want ErrNoUses", err)
+	}
+}
+
+func TestQuotaCommittedUntilSync(t *testing.T) {
+	b := newBackend(t, 10)
+	quota := newQuota(t, b)
+	if err := quota.Do("exports", 4, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	// The library keeps the license uses of its last server sync.
+	setTotalUses(b, "exports", 0)
+	if usage, err := quota.Usage("exports"); err != nil || usage.Committed != 4 || usage.Remaining != 6 {
+		t.Fatalf("usage %+v before the server sync, %v", usage, err)
+	}
+	if _, err := quota.Reserve("exports", 7); !errors.Is(err, lexactivator.ErrMeterAttributeUsesLimitReached) {
+		t.Fatalf("error %v, want ErrMeterAttributeUsesLimitReached", err)
+	}
+
+	setTotalUses(b, "exports", 4)
+	if usage, err := quota.Usage("exports"); err != nil || usage.Committed != 0 || usage.Remaining != 6 {
+		t.Fatalf("usage %+v after the server sync, %v", usage, err)
+	}
+}
+
+func TestQuotaDoFailure(t *testing.T) {
+	b := newBackend(t, 10)
+	quota := newQuota(t, b)
+	workErr := errors.New("export failed")
+	if err := quota.Do("exports", 4, func() error { return workErr }); err != workErr {
+		t.Fatalf("error %v, want the work error", err)
+	}
+	if uses := activationUses(b, "exports"); uses != 0 {
+		t.Fatalf("%d uses reported for failed work", uses)
+	}
+	if usage, err := quota.Usage("exports"); err != nil || usage.Remaining != 10 {
+		t.Fatalf("usage %+v, %v", usage, err)
+	}
+}
+
+func TestQuotaConcurrentReservations(t *testing.T) {
+	for _, stale := range []bool{false, true} {
+		b := newBackend(t, 10)
+		var backend lexactivator.Backend = b
+		if stale {
+			backend = staleBackend{b}
+		}
+		quota := newQuota(t, backend)
+		var (
+			wg       sync.WaitGroup
+			mu       sync.Mutex
+			done     int
+			exceeded int
+		)
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := quota.Do("exports", 1, func() error { return nil })
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case err == nil:
+					done++
+				case errors.Is(err, lexactivator.ErrMeterAttributeUsesLimitReached):
+					exceeded++
+				default:
+					t.Error(err)
+				}
+			}()
+		}
+		wg.Wait()
+		if done != 10 || exceeded != 40 {
+			t.Fatalf("stale %v: %d done and %d exceeding the quota, want 10 and 40", stale, done, exceeded)
+		}
+		if uses := activationUses(b, "exports"); uses != 10 {
+			t.Fatalf("stale %v: %d uses reported, want 10", stale, uses)
+		}
+	}
+}
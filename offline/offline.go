@@ -0,0 +1,128 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+// Package offline wraps the file based offline activation functions of
+// LexActivator with []byte and io.Reader based ones, and provides a
+// workflow persisted to disk so that air-gapped customers can complete an
+// offline activation across several sessions:
+//
+//	helper := offline.New(client.Backend())
+//	workflow, err := offline.OpenWorkflow(statePath, helper, offline.KindLicense)
+//	...
+//	request, err := workflow.Request()    // hand over to the customer
+//	...
+//	err = workflow.Complete(responseReader) // next session
+package offline
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+)
+
+// Helper calls the offline activation functions of a backend through
+// temporary files, which it removes once done.
+type Helper struct {
+	backend lexactivator.Backend
+
+	// TempDir is the directory the temporary files are created in.
+	// Defaults to os.TempDir().
+	TempDir string
+}
+
+// New returns a helper calling into backend, or into the native library
+// if backend is nil.
+func New(backend lexactivator.Backend) *Helper {
+	if backend == nil {
+		backend = lexactivator.Native()
+	}
+	return &Helper{backend: backend}
+}
+
+// ActivationRequest generates the offline activation request, see
+// GenerateOfflineActivationRequest().
+func (h *Helper) ActivationRequest() ([]byte, error) {
+	return h.generate(h.backend.GenerateOfflineActivationRequest)
+}
+
+// ActivateLicense activates the license with the offline activation
+// response read from response, see ActivateLicenseOffline(). Informational
+// statuses such as LA_EXPIRED are returned as errors, like StatusToError()
+// does.
+func (h *Helper) ActivateLicense(response io.Reader) error {
+	return h.consume(response, h.backend.ActivateLicenseOffline)
+}
+
+// DeactivationRequest generates the offline deactivation request, see
+// GenerateOfflineDeactivationRequest().
+func (h *Helper) DeactivationRequest() ([]byte, error) {
+	return h.generate(h.backend.GenerateOfflineDeactivationRequest)
+}
+
+// TrialActivationRequest generates the offline trial activation request,
+// see GenerateOfflineTrialActivationRequest().
+func (h *Helper) TrialActivationRequest() ([]byte, error) {
+	return h.generate(h.backend.GenerateOfflineTrialActivationRequest)
+}
+
+// ActivateTrial activates the trial with the offline trial activation
+// response read from response, see ActivateTrialOffline().
+func (h *Helper) ActivateTrial(response io.Reader) error {
+	return h.consume(response, h.backend.ActivateTrialOffline)
+}
+
+// ActivateLicenseBytes is ActivateLicense() for a response held in memory.
+func (h *Helper) ActivateLicenseBytes(response []byte) error {
+	return h.ActivateLicense(bytes.NewReader(response))
+}
+
+// ActivateTrialBytes is ActivateTrial() for a response held in memory.
+func (h *Helper) ActivateTrialBytes(response []byte) error {
+	return h.ActivateTrial(bytes.NewReader(response))
+}
+
+// generate calls a request generating function with a temporary file path
+// and returns the content of the file.
+func (h *Helper) generate(call func(filePath string) int) ([]byte, error) {
+	dir, err := ioutil.TempDir(h.TempDir, "lexactivator-offline")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "request.dat")
+	if err := lexactivator.StatusToError(call(path)); err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("offline: reading the generated request: %w", err)
+	}
+	return data, nil
+}
+
+// consume writes response to a temporary file and calls a response
+// consuming function with its path.
+func (h *Helper) consume(response io.Reader, call func(filePath string) int) error {
+	dir, err := ioutil.TempDir(h.TempDir, "lexactivator-offline")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "response.dat")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, response); err != nil {
+		f.Close()
+		return fmt.Errorf("offline: reading the response: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return lexactivator.StatusToError(call(path))
+}
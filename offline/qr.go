@@ -0,0 +1,514 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package offline
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// The QR code encoder below follows ISO/IEC 18004. It only implements what
+// the offline payloads need: byte mode and the medium error correction
+// level, which recovers about 15% of damaged codewords.
+
+// ErrQRCodeTooLarge is returned when the data does not fit in a QR code.
+var ErrQRCodeTooLarge = errors.New("offline: data too large for a QR code")
+
+// qrQuietZone is the width of the blank border required around a code, in
+// modules.
+const qrQuietZone = 4
+
+// Error correction codewords per block and number of blocks for the medium
+// level, indexed by version.
+var (
+	qrECCPerBlock = [41]int{-1,
+		10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26,
+		26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28}
+	qrBlocks = [41]int{-1,
+		1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16,
+		17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49}
+)
+
+// qrFormatLevel is the format information value of the medium level.
+const qrFormatLevel = 0
+
+// WriteQRCode writes data as a QR code PNG with modules of scale pixels,
+// or 4 if scale is not positive.
+func WriteQRCode(w io.Writer, data []byte, scale int) error {
+	img, err := QRCode(data, scale)
+	if err != nil {
+		return err
+	}
+	return png.Encode(w, img)
+}
+
+// QRCode renders data as a QR code image with modules of scale pixels, or
+// 4 if scale is not positive. The smallest version holding data is used.
+func QRCode(data []byte, scale int) (*image.Gray, error) {
+	if scale <= 0 {
+		scale = 4
+	}
+	code, err := newQRCode(data)
+	if err != nil {
+		return nil, err
+	}
+	width := (code.size + 2*qrQuietZone) * scale
+	img := image.NewGray(image.Rect(0, 0, width, width))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+	for y := 0; y < code.size; y++ {
+		for x := 0; x < code.size; x++ {
+			if !code.modules[y][x] {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetGray((x+qrQuietZone)*scale+dx, (y+qrQuietZone)*scale+dy, color.Gray{})
+				}
+			}
+		}
+	}
+	return img, nil
+}
+
+type qrCode struct {
+	version    int
+	size       int
+	modules    [][]bool
+	isFunction [][]bool
+}
+
+func newQRCode(data []byte) (*qrCode, error) {
+	version := 0
+	for v := 1; v <= 40; v++ {
+		if qrDataBits(v, len(data)) <= qrDataCodewords(v)*8 {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrQRCodeTooLarge
+	}
+	code := &qrCode{version: version, size: version*4 + 17}
+	code.modules = make([][]bool, code.size)
+	code.isFunction = make([][]bool, code.size)
+	for i := range code.modules {
+		code.modules[i] = make([]bool, code.size)
+		code.isFunction[i] = make([]bool, code.size)
+	}
+	code.drawFunctionPatterns()
+	code.drawCodewords(code.addECCAndInterleave(qrEncodeData(version, data)))
+
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		code.applyMask(mask)
+		code.drawFormatBits(mask)
+		if penalty := code.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		code.applyMask(mask)
+	}
+	code.applyMask(best)
+	code.drawFormatBits(best)
+	return code, nil
+}
+
+// qrDataBits returns the number of bits of n bytes in byte mode.
+func qrDataBits(version, n int) int {
+	countBits := 8
+	if version >= 10 {
+		countBits = 16
+	}
+	if n >= 1<<uint(countBits) {
+		return 1 << 30
+	}
+	return 4 + countBits + 8*n
+}
+
+// qrRawDataModules returns the number of modules available for codewords.
+func qrRawDataModules(version int) int {
+	result := (16*version+128)*version + 64
+	if version >= 2 {
+		alignments := version/7 + 2
+		result -= (25*alignments-10)*alignments - 55
+		if version >= 7 {
+			result -= 36
+		}
+	}
+	return result
+}
+
+func qrDataCodewords(version int) int {
+	return qrRawDataModules(version)/8 - qrECCPerBlock[version]*qrBlocks[version]
+}
+
+// qrEncodeData returns the data codewords: mode, count, data, terminator
+// and padding.
+func qrEncodeData(version int, data []byte) []byte {
+	var bits qrBitBuffer
+	bits.append(0x4, 4)
+	if version >= 10 {
+		bits.append(len(data), 16)
+	} else {
+		bits.append(len(data), 8)
+	}
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	capacity := qrDataCodewords(version) * 8
+	terminator := capacity - len(bits)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bits.append(0, terminator)
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xec; len(bits) < capacity; pad ^= 0xec ^ 0x11 {
+		bits.append(pad, 8)
+	}
+	codewords := make([]byte, len(bits)/8)
+	for i, bit := range bits {
+		if bit {
+			codewords[i>>3] |= 1 << uint(7-i&7)
+		}
+	}
+	return codewords
+}
+
+type qrBitBuffer []bool
+
+func (b *qrBitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, (value>>uint(i))&1 != 0)
+	}
+}
+
+// addECCAndInterleave splits data into blocks, appends their error
+// correction codewords and interleaves them.
+func (c *qrCode) addECCAndInterleave(data []byte) []byte {
+	blocks := qrBlocks[c.version]
+	eccLen := qrECCPerBlock[c.version]
+	rawCodewords := qrRawDataModules(c.version) / 8
+	shortBlocks := blocks - rawCodewords%blocks
+	shortBlockLen := rawCodewords / blocks
+
+	divisor := qrReedSolomonDivisor(eccLen)
+	all := make([][]byte, blocks)
+	k := 0
+	for i := range all {
+		n := shortBlockLen - eccLen
+		if i >= shortBlocks {
+			n++
+		}
+		block := append([]byte(nil), data[k:k+n]...)
+		k += n
+		ecc := qrReedSolomonRemainder(block, divisor)
+		if i < shortBlocks {
+			block = append(block, 0)
+		}
+		all[i] = append(block, ecc...)
+	}
+	result := make([]byte, 0, rawCodewords)
+	for i := range all[0] {
+		for j, block := range all {
+			// Short blocks have a placeholder after their data.
+			if i != shortBlockLen-eccLen || j >= shortBlocks {
+				result = append(result, block[i])
+			}
+		}
+	}
+	return result
+}
+
+func qrReedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = qrMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = qrMultiply(root, 0x02)
+	}
+	return result
+}
+
+func qrReedSolomonRemainder(data, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i := range result {
+			result[i] ^= qrMultiply(divisor[i], factor)
+		}
+	}
+	return result
+}
+
+// qrMultiply multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1.
+func qrMultiply(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11d)
+		z ^= int((y>>uint(i))&1) * int(x)
+	}
+	return byte(z)
+}
+
+func (c *qrCode) setFunction(x, y int, dark bool) {
+	c.modules[y][x] = dark
+	c.isFunction[y][x] = true
+}
+
+func (c *qrCode) drawFunctionPatterns() {
+	for i := 0; i < c.size; i++ {
+		c.setFunction(6, i, i%2 == 0)
+		c.setFunction(i, 6, i%2 == 0)
+	}
+	c.drawFinder(3, 3)
+	c.drawFinder(c.size-4, 3)
+	c.drawFinder(3, c.size-4)
+
+	positions := c.alignmentPositions()
+	n := len(positions)
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			// Skip the corners taken by the finder patterns.
+			if i == 0 && j == 0 || i == 0 && j == n-1 || i == n-1 && j == 0 {
+				continue
+			}
+			c.drawAlignment(positions[i], positions[j])
+		}
+	}
+	// Reserve the format areas, drawn for real once the mask is chosen.
+	c.drawFormatBits(0)
+	c.drawVersion()
+}
+
+func (c *qrCode) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= c.size || yy < 0 || yy >= c.size {
+				continue
+			}
+			distance := qrMax(qrAbs(dx), qrAbs(dy))
+			c.setFunction(xx, yy, distance != 2 && distance != 4)
+		}
+	}
+}
+
+func (c *qrCode) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			c.setFunction(x+dx, y+dy, qrMax(qrAbs(dx), qrAbs(dy)) != 1)
+		}
+	}
+}
+
+func (c *qrCode) alignmentPositions() []int {
+	if c.version == 1 {
+		return nil
+	}
+	n := c.version/7 + 2
+	step := (c.version*8 + n*3 + 5) / (n*4 - 4) * 2
+	positions := make([]int, n)
+	positions[0] = 6
+	for i, pos := n-1, c.size-7; i >= 1; i, pos = i-1, pos-step {
+		positions[i] = pos
+	}
+	return positions
+}
+
+func (c *qrCode) drawFormatBits(mask int) {
+	data := qrFormatLevel<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+
+	for i := 0; i <= 5; i++ {
+		c.setFunction(8, i, qrBit(bits, i))
+	}
+	c.setFunction(8, 7, qrBit(bits, 6))
+	c.setFunction(8, 8, qrBit(bits, 7))
+	c.setFunction(7, 8, qrBit(bits, 8))
+	for i := 9; i < 15; i++ {
+		c.setFunction(14-i, 8, qrBit(bits, i))
+	}
+
+	for i := 0; i < 8; i++ {
+		c.setFunction(c.size-1-i, 8, qrBit(bits, i))
+	}
+	for i := 8; i < 15; i++ {
+		c.setFunction(8, c.size-15+i, qrBit(bits, i))
+	}
+	c.setFunction(8, c.size-8, true)
+}
+
+func (c *qrCode) drawVersion() {
+	if c.version < 7 {
+		return
+	}
+	rem := c.version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1f25)
+	}
+	bits := c.version<<12 | rem
+	for i := 0; i < 18; i++ {
+		bit := qrBit(bits, i)
+		a, b := c.size-11+i%3, i/3
+		c.setFunction(a, b, bit)
+		c.setFunction(b, a, bit)
+	}
+}
+
+// drawCodewords places the codewords in the zigzag order, upwards and
+// downwards in columns of two modules from the right.
+func (c *qrCode) drawCodewords(data []byte) {
+	i := 0
+	for right := c.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < c.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = c.size - 1 - vert
+				}
+				if !c.isFunction[y][x] && i < len(data)*8 {
+					c.modules[y][x] = qrBit(int(data[i>>3]), 7-i&7)
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask inverts the data modules selected by mask. Applying it twice
+// undoes it.
+func (c *qrCode) applyMask(mask int) {
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !c.isFunction[y][x] {
+				c.modules[y][x] = !c.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores the symbol with the four rules of the standard; the mask
+// with the lowest score is used.
+func (c *qrCode) penalty() int {
+	at := func(x, y int, transposed bool) bool {
+		if transposed {
+			return c.modules[x][y]
+		}
+		return c.modules[y][x]
+	}
+	finderLike := []bool{true, false, true, true, true, false, true}
+	result := 0
+	for _, transposed := range []bool{false, true} {
+		for y := 0; y < c.size; y++ {
+			run := 1
+			for x := 1; x <= c.size; x++ {
+				if x < c.size && at(x, y, transposed) == at(x-1, y, transposed) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					result += 3 + run - 5
+				}
+				run = 1
+			}
+			for x := 0; x+7 <= c.size; x++ {
+				match := true
+				for i, dark := range finderLike {
+					if at(x+i, y, transposed) != dark {
+						match = false
+						break
+					}
+				}
+				if match && (c.lightRun(x-4, x, y, transposed, at) || c.lightRun(x+7, x+11, y, transposed, at)) {
+					result += 40
+				}
+			}
+		}
+	}
+	dark := 0
+	for y := 0; y < c.size; y++ {
+		for x := 0; x < c.size; x++ {
+			if c.modules[y][x] {
+				dark++
+			}
+			if x+1 < c.size && y+1 < c.size {
+				color := c.modules[y][x]
+				if color == c.modules[y][x+1] && color == c.modules[y+1][x] && color == c.modules[y+1][x+1] {
+					result += 3
+				}
+			}
+		}
+	}
+	total := c.size * c.size
+	percent := dark * 100 / total
+	result += qrAbs(percent-50) / 5 * 10
+	return result
+}
+
+// lightRun reports whether the modules from x0 to x1 of row y are light.
+// Modules outside the symbol count as light.
+func (c *qrCode) lightRun(x0, x1, y int, transposed bool, at func(x, y int, transposed bool) bool) bool {
+	for x := x0; x < x1; x++ {
+		if x >= 0 && x < c.size && at(x, y, transposed) {
+			return false
+		}
+	}
+	return true
+}
+
+func qrBit(value, i int) bool {
+	return (value>>uint(i))&1 != 0
+}
+
+func qrAbs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func qrMax(x, y int) int {
+	if x > y {
+		return x
+	}
+	return y
+}
@@ -0,0 +1,139 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package offline
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// qrTestPayload returns n bytes covering the whole byte range.
+func qrTestPayload(n int) []byte {
+	payload := make([]byte, n)
+	for i := range payload {
+		payload[i] = byte(i*37 + 11)
+	}
+	return payload
+}
+
+// qrMatrix renders the modules of code one row per line, "#" for dark.
+func qrMatrix(code *qrCode) string {
+	var b strings.Builder
+	for y := 0; y < code.size; y++ {
+		for x := 0; x < code.size; x++ {
+			if code.modules[y][x] {
+				b.WriteByte('#')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// qrVersion1 is the symbol of qrTestPayload(12), version 1 with mask 1.
+const qrVersion1 = `#######.##.#..#######
+#.....#..##.#.#.....#
+#.###.#.#.#.#.#.###.#
+#.###.#...###.#.###.#
+#.###.#...#.#.#.###.#
+#.....#.#.#...#.....#
+#######.#.#.#.#######
+..........##.........
+#.#...##.####..#..#.#
+..#..#.#.#...##....##
+##..###......#.######
+.##.....#...#..#.#.##
+.#.######.#....#..##.
+........#.......##...
+#######.#..##.....###
+#.....#..#..##...#...
+#.###.#..#.##.#.#....
+#.###.#.....#.#...#..
+#.###.#.####.##..#.##
+#.....#..#.#.#.##.#..
+#######.##........#.#
+`
+
+// The expected symbols were rendered by rsc.io/qr/coding for the same data,
+// version, medium level and mask; the larger ones are compared by the
+// SHA-256 of their qrMatrix().
+func TestQRCodeMatrices(t *testing.T) {
+	tests := []struct {
+		size    int
+		version int
+		sha256  string
+	}{
+		{12, 1, "829ff407d6b182a8b5e75270b4cfdff6912f00e349341f7367435acec78fd7ff"},
+		{120, 7, "81c2c7aa74f0fcd3831adbf62308742613eecdf8737ac342682a3ea7b2552c93"},
+		{200, 10, "93868c0e4c4b1c27c41dc6c501328c132285587f3a0fbed62afff9975d6c5bf5"},
+		{2300, 40, "d3b75ea28a7964e6f52194f3c9aa1238bd8ea03e01216e649e61f0a3fdb5adf4"},
+	}
+	for _, test := range tests {
+		code, err := newQRCode(qrTestPayload(test.size))
+		if err != nil {
+			t.Fatalf("%d bytes: %v", test.size, err)
+		}
+		if code.version != test.version {
+			t.Errorf("%d bytes: version %d, want %d", test.size, code.version, test.version)
+			continue
+		}
+		matrix := qrMatrix(code)
+		if test.version == 1 && matrix != qrVersion1 {
+			t.Errorf("version 1 symbol:\n%swant:\n%s", matrix, qrVersion1)
+		}
+		sum := sha256.Sum256([]byte(matrix))
+		if got := hex.EncodeToString(sum[:]); got != test.sha256 {
+			t.Errorf("version %d symbol: SHA-256 %s, want %s", test.version, got, test.sha256)
+		}
+	}
+}
+
+func TestQRCodeTooLarge(t *testing.T) {
+	if _, err := newQRCode(qrTestPayload(2331)); err != nil {
+		t.Fatalf("2331 bytes: %v", err)
+	}
+	if _, err := newQRCode(qrTestPayload(2332)); err != ErrQRCodeTooLarge {
+		t.Fatalf("2332 bytes: error %v, want ErrQRCodeTooLarge", err)
+	}
+}
+
+func TestEncodeQRCodes(t *testing.T) {
+	const scale = 3
+	payload := qrTestPayload(1000)
+	images, err := EncodeQRCodes(payload, scale)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chunks := EncodeChunks(payload, QRChunkSize)
+	if len(images) != len(chunks) {
+		t.Fatalf("%d images for %d chunks", len(images), len(chunks))
+	}
+	for i, data := range images {
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("image %d: %v", i, err)
+		}
+		code, err := newQRCode([]byte(chunks[i]))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if width := (code.size + 2*qrQuietZone) * scale; img.Bounds().Dx() != width || img.Bounds().Dy() != width {
+			t.Fatalf("image %d: bounds %v, want %dx%d", i, img.Bounds(), width, width)
+		}
+		for y := -qrQuietZone; y < code.size+qrQuietZone; y++ {
+			for x := -qrQuietZone; x < code.size+qrQuietZone; x++ {
+				r, _, _, _ := img.At((x+qrQuietZone)*scale+scale/2, (y+qrQuietZone)*scale+scale/2).RGBA()
+				dark := x >= 0 && y >= 0 && x < code.size && y < code.size && code.modules[y][x]
+				if (r == 0) != dark {
+					t.Fatalf("image %d: module (%d, %d) dark %v, want %v", i, x, y, r == 0, dark)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package offline
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// chunkPrefix starts every line of an encoded payload. The digit is the
+// format version.
+const chunkPrefix = "LXA1"
+
+const (
+	// DefaultChunkSize is the number of payload bytes per chunk of
+	// EncodeText(), short enough for a chunk to be typed in one go.
+	DefaultChunkSize = 48
+
+	// QRChunkSize is the number of payload bytes per chunk of the QR codes
+	// of EncodeQRCodes(), small enough for phone cameras to scan them.
+	QRChunkSize = 384
+
+	// maxChunks bounds the number of chunks a decoded payload may claim, so
+	// that a corrupt total cannot make DecodeText() report millions of
+	// missing chunks.
+	maxChunks = 4096
+)
+
+var (
+	// ErrNoChunks is returned when decoding a text without any chunk.
+	ErrNoChunks = errors.New("offline: no chunks found")
+
+	// ErrPayloadChecksum is returned when the chunks decode to a payload
+	// that does not match its checksum.
+	ErrPayloadChecksum = errors.New("offline: payload checksum mismatch")
+)
+
+// CorruptChunkError is returned when a chunk cannot be decoded or does not
+// match its checksum.
+type CorruptChunkError struct {
+	// Line is the line number of the chunk in the decoded text.
+	Line int
+
+	// Index is the 1-based index of the chunk, or 0 if it could not be
+	// read.
+	Index int
+
+	Err error
+}
+
+func (e *CorruptChunkError) Error() string {
+	if e.Index == 0 {
+		return fmt.Sprintf("offline: corrupt chunk on line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("offline: corrupt chunk %d on line %d: %v", e.Index, e.Line, e.Err)
+}
+
+func (e *CorruptChunkError) Unwrap() error {
+	return e.Err
+}
+
+// MissingChunksError is returned when some chunks of a payload are
+// missing.
+type MissingChunksError struct {
+	// Missing are the 1-based indexes of the missing chunks.
+	Missing []int
+	Total   int
+}
+
+func (e *MissingChunksError) Error() string {
+	missing := make([]string, len(e.Missing))
+	for i, index := range e.Missing {
+		missing[i] = strconv.Itoa(index)
+	}
+	return fmt.Sprintf("offline: missing chunks %s of %d", strings.Join(missing, ", "), e.Total)
+}
+
+// EncodeChunks splits payload into base64 chunks of chunkSize bytes, or
+// DefaultChunkSize if chunkSize is not positive. Each chunk is a line of
+// the form:
+//
+//	LXA1 <payload id> <index>/<total> <crc32> <base64 data>
+//
+// where the payload id is derived from the SHA-256 of the payload, so that
+// the decoder detects chunks of different payloads and verifies the
+// reassembled payload. The CRC-32 covers the payload id, index and total as
+// well as the data, so a mistyped header is caught like mistyped data.
+//
+// A payload may not need more than 4096 chunks.
+func EncodeChunks(payload []byte, chunkSize int) []string {
+	if chunkSize <= 0 {
+		chunkSize = DefaultChunkSize
+	}
+	id := payloadID(payload)
+	total := (len(payload) + chunkSize - 1) / chunkSize
+	if total == 0 {
+		total = 1
+	}
+	chunks := make([]string, 0, total)
+	for i := 0; i < total; i++ {
+		end := (i + 1) * chunkSize
+		if end > len(payload) {
+			end = len(payload)
+		}
+		data := payload[i*chunkSize : end]
+		chunks = append(chunks, fmt.Sprintf("%s %s %d/%d %08x %s",
+			chunkPrefix, id, i+1, total, chunkChecksum(id, i+1, total, data), base64.StdEncoding.EncodeToString(data)))
+	}
+	return chunks
+}
+
+// EncodeText encodes payload as a text block of DefaultChunkSize chunks,
+// one per line, see EncodeChunks().
+func EncodeText(payload []byte) string {
+	return strings.Join(EncodeChunks(payload, DefaultChunkSize), "\n") + "\n"
+}
+
+// DecodeText reassembles the payload of a text block written by
+// EncodeText() or of chunks scanned from the QR codes of EncodeQRCodes().
+// The chunks may come in any order and repeat, and lines that are not
+// chunks, such as the text around a pasted block, are ignored. Whitespace
+// within the base64 data is ignored as well.
+//
+// It returns a *CorruptChunkError for a chunk that does not match its
+// checksum, a *MissingChunksError listing the chunks to enter again, and
+// ErrNoChunks if there are none.
+func DecodeText(text string) ([]byte, error) {
+	var (
+		id     string
+		total  int
+		chunks map[int][]byte
+	)
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || fields[0] != chunkPrefix {
+			continue
+		}
+		chunkID, index, chunkTotal, data, err := decodeChunk(fields)
+		if err != nil {
+			return nil, &CorruptChunkError{Line: line, Index: index, Err: err}
+		}
+		if chunks == nil {
+			id, total, chunks = chunkID, chunkTotal, map[int][]byte{}
+		} else if chunkID != id || chunkTotal != total {
+			return nil, &CorruptChunkError{Line: line, Index: index, Err: errors.New("chunk of another payload")}
+		}
+		if previous, ok := chunks[index]; ok && !bytes.Equal(previous, data) {
+			return nil, &CorruptChunkError{Line: line, Index: index, Err: errors.New("differs from a previous copy")}
+		}
+		chunks[index] = data
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if chunks == nil {
+		return nil, ErrNoChunks
+	}
+	var missing []int
+	for index := 1; index <= total; index++ {
+		if _, ok := chunks[index]; !ok {
+			missing = append(missing, index)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, &MissingChunksError{Missing: missing, Total: total}
+	}
+	indexes := make([]int, 0, total)
+	for index := range chunks {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+	var payload []byte
+	for _, index := range indexes {
+		payload = append(payload, chunks[index]...)
+	}
+	if payloadID(payload) != id {
+		return nil, ErrPayloadChecksum
+	}
+	return payload, nil
+}
+
+// decodeChunk decodes the fields of a chunk line. The base64 data may be
+// split over several fields.
+func decodeChunk(fields []string) (id string, index, total int, data []byte, err error) {
+	if len(fields) < 4 {
+		return "", 0, 0, nil, errors.New("truncated")
+	}
+	id = fields[1]
+	parts := strings.SplitN(fields[2], "/", 2)
+	if len(parts) != 2 {
+		return "", 0, 0, nil, fmt.Errorf("invalid index %q", fields[2])
+	}
+	index, err = strconv.Atoi(parts[0])
+	if err != nil || index < 1 {
+		return "", 0, 0, nil, fmt.Errorf("invalid index %q", fields[2])
+	}
+	total, err = strconv.Atoi(parts[1])
+	if err != nil || total < index || total > maxChunks {
+		return "", 0, 0, nil, fmt.Errorf("invalid index %q", fields[2])
+	}
+	checksum, err := strconv.ParseUint(fields[3], 16, 32)
+	if err != nil {
+		return "", index, 0, nil, fmt.Errorf("invalid checksum %q", fields[3])
+	}
+	data, err = base64.StdEncoding.DecodeString(strings.Join(fields[4:], ""))
+	if err != nil {
+		return "", index, 0, nil, err
+	}
+	if chunkChecksum(id, index, total, data) != uint32(checksum) {
+		return "", index, 0, nil, errors.New("checksum mismatch")
+	}
+	return id, index, total, data, nil
+}
+
+// chunkChecksum returns the CRC-32 of the header fields and data of a
+// chunk.
+func chunkChecksum(id string, index, total int, data []byte) uint32 {
+	crc := crc32.ChecksumIEEE([]byte(fmt.Sprintf("%s %d/%d ", id, index, total)))
+	return crc32.Update(crc, crc32.IEEETable, data)
+}
+
+func payloadID(payload []byte) string {
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:6])
+}
+
+// EncodeQRCodes encodes payload as QRChunkSize chunks, see EncodeChunks(),
+// and renders each chunk as a QR code PNG with modules of scale pixels.
+// The text scanned from the codes is decoded with DecodeText().
+func EncodeQRCodes(payload []byte, scale int) ([][]byte, error) {
+	chunks := EncodeChunks(payload, QRChunkSize)
+	images := make([][]byte, 0, len(chunks))
+	for _, chunk := range chunks {
+		var buf bytes.Buffer
+		if err := WriteQRCode(&buf, []byte(chunk), scale); err != nil {
+			return nil, err
+		}
+		images = append(images, buf.Bytes())
+	}
+	return images, nil
+}
+
+// ActivationRequestText generates the offline activation request as a text
+// block, see EncodeText().
+func (h *Helper) ActivationRequestText() (string, error) {
+	request, err := h.ActivationRequest()
+	if err != nil {
+		return "", err
+	}
+	return EncodeText(request), nil
+}
+
+// ActivationRequestQRCodes generates the offline activation request as QR
+// code PNGs, see EncodeQRCodes().
+func (h *Helper) ActivationRequestQRCodes(scale int) ([][]byte, error) {
+	request, err := h.ActivationRequest()
+	if err != nil {
+		return nil, err
+	}
+	return EncodeQRCodes(request, scale)
+}
+
+// ActivateLicenseText activates the license with an offline activation
+// response pasted or scanned as text, see DecodeText(). Nothing is passed
+// to ActivateLicenseOffline() unless the response is complete and intact.
+func (h *Helper) ActivateLicenseText(text string) error {
+	response, err := DecodeText(text)
+	if err != nil {
+		return err
+	}
+	return h.ActivateLicenseBytes(response)
+}
@@ -0,0 +1,163 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package offline
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testChunkSize = 16
+
+func testChunks(t *testing.T) ([]byte, []string) {
+	t.Helper()
+	payload := qrTestPayload(100)
+	chunks := EncodeChunks(payload, testChunkSize)
+	if len(chunks) != 7 {
+		t.Fatalf("%d chunks, want 7", len(chunks))
+	}
+	return payload, chunks
+}
+
+func decodeLines(lines []string) ([]byte, error) {
+	return DecodeText(strings.Join(lines, "\n"))
+}
+
+// tamper replaces the data of chunk with data, keeping its checksum unless
+// fixChecksum is true.
+func tamper(chunk string, data []byte, fixChecksum bool) string {
+	fields := strings.Fields(chunk)
+	if fixChecksum {
+		var index, total int
+		fmt.Sscanf(fields[2], "%d/%d", &index, &total)
+		fields[3] = fmt.Sprintf("%08x", chunkChecksum(fields[1], index, total, data))
+	}
+	fields[4] = base64.StdEncoding.EncodeToString(data)
+	return strings.Join(fields, " ")
+}
+
+func TestDecodeTextRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, testChunkSize, 100} {
+		payload := qrTestPayload(size)
+		got, err := DecodeText(EncodeText(payload))
+		if err != nil {
+			t.Fatalf("%d bytes: %v", size, err)
+		}
+		if !bytes.Equal(got, payload) {
+			t.Fatalf("%d bytes: decoded %x", size, got)
+		}
+	}
+}
+
+func TestDecodeTextShuffled(t *testing.T) {
+	payload, chunks := testChunks(t)
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 10; i++ {
+		random.Shuffle(len(chunks), func(i, j int) { chunks[i], chunks[j] = chunks[j], chunks[i] })
+		got, err := decodeLines(chunks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, payload) {
+			t.Fatalf("order %v: decoded %x", chunks, got)
+		}
+	}
+}
+
+func TestDecodeTextDuplicated(t *testing.T) {
+	payload, chunks := testChunks(t)
+	lines := append([]string{chunks[3], chunks[0]}, chunks...)
+	lines = append(lines, chunks[3])
+	got, err := decodeLines(lines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Fatalf("decoded %x", got)
+	}
+}
+
+func TestDecodeTextSurroundingText(t *testing.T) {
+	payload, chunks := testChunks(t)
+	var lines []string
+	lines = append(lines, "Offline activation request:", "")
+	for _, chunk := range chunks {
+		// Split the base64 data as a mail client wrapping lines would.
+		lines = append(lines, "  "+chunk[:len(chunk)-5]+" "+chunk[len(chunk)-5:])
+	}
+	lines = append(lines, "", "-- ", "Sent from the field")
+	got, err := decodeLines(lines)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Fatalf("decoded %x", got)
+	}
+}
+
+func TestDecodeTextMissing(t *testing.T) {
+	_, chunks := testChunks(t)
+	lines := []string{chunks[6], chunks[2], chunks[0], chunks[2]}
+	_, err := decodeLines(lines)
+	var missingErr *MissingChunksError
+	if !errors.As(err, &missingErr) {
+		t.Fatalf("error %v, want a *MissingChunksError", err)
+	}
+	if want := []int{2, 4, 5, 6}; !reflect.DeepEqual(missingErr.Missing, want) || missingErr.Total != 7 {
+		t.Fatalf("missing %v of %d, want %v of 7", missingErr.Missing, missingErr.Total, want)
+	}
+
+	if _, err := DecodeText("no chunks here\n"); err != ErrNoChunks {
+		t.Fatalf("error %v, want ErrNoChunks", err)
+	}
+}
+
+func TestDecodeTextCorrupted(t *testing.T) {
+	payload, chunks := testChunks(t)
+	changed := append([]byte(nil), payload[2*testChunkSize:3*testChunkSize]...)
+	changed[0] ^= 0xff
+	other := EncodeChunks(qrTestPayload(101), testChunkSize)
+
+	tests := []struct {
+		name  string
+		line  string
+		index int
+	}{
+		{"checksum mismatch", tamper(chunks[2], changed, false), 3},
+		{"invalid base64", chunks[2][:len(chunks[2])-3] + "!!!", 3},
+		{"invalid checksum", strings.Replace(chunks[2], " 3/7 ", " 3/7 checksum ", 1), 3},
+		{"invalid index", strings.Replace(chunks[2], " 3/7 ", " 3of7 ", 1), 0},
+		{"too many chunks", strings.Replace(chunks[2], " 3/7 ", " 3/100000000 ", 1), 0},
+		{"mistyped index", strings.Replace(chunks[2], " 3/7 ", " 4/7 ", 1), 4},
+		{"mistyped total", strings.Replace(chunks[2], " 3/7 ", " 3/8 ", 1), 3},
+		{"truncated", chunkPrefix + " " + strings.Fields(chunks[2])[1], 0},
+		{"differs from a previous copy", tamper(chunks[2], changed, true), 3},
+		{"chunk of another payload", other[2], 3},
+	}
+	for _, test := range tests {
+		lines := append(append([]string(nil), chunks...), test.line)
+		_, err := decodeLines(lines)
+		var corruptErr *CorruptChunkError
+		if !errors.As(err, &corruptErr) {
+			t.Errorf("%s: error %v, want a *CorruptChunkError", test.name, err)
+			continue
+		}
+		if corruptErr.Line != len(lines) || corruptErr.Index != test.index {
+			t.Errorf("%s: chunk %d on line %d, want chunk %d on line %d", test.name, corruptErr.Index, corruptErr.Line, test.index, len(lines))
+		}
+	}
+
+	// A chunk consistent with its own checksum but not with the payload is
+	// only caught by the payload checksum.
+	lines := append([]string(nil), chunks...)
+	lines[2] = tamper(chunks[2], changed, true)
+	if _, err := decodeLines(lines); err != ErrPayloadChecksum {
+		t.Fatalf("error %v, want ErrPayloadChecksum", err)
+	}
+}
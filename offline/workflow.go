@@ -0,0 +1,227 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package offline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+)
+
+// Kind is the kind of offline activation a Workflow performs.
+type Kind string
+
+const (
+	KindLicense Kind = "license"
+	KindTrial   Kind = "trial"
+)
+
+// Stage is the stage of a Workflow.
+type Stage string
+
+const (
+	// StageNew is the stage of a workflow without a request.
+	StageNew Stage = "new"
+
+	// StageRequestGenerated is the stage once the request is generated.
+	StageRequestGenerated Stage = "request-generated"
+
+	// StageAwaitingResponse is the stage once the request is marked as
+	// handed over with MarkSent().
+	StageAwaitingResponse Stage = "awaiting-response"
+
+	// StageActivated is the final stage, once the response is consumed.
+	StageActivated Stage = "activated"
+)
+
+// ErrInvalidTransition is returned when a Workflow method is called in a
+// stage it does not apply to.
+var ErrInvalidTransition = errors.New("offline: invalid workflow transition")
+
+// WorkflowState is the state of a Workflow as persisted to disk.
+type WorkflowState struct {
+	Kind  Kind  `json:"kind"`
+	Stage Stage `json:"stage"`
+
+	// Request is the generated request, kept so that it can be handed
+	// over again in a later session.
+	Request []byte `json:"request,omitempty"`
+
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	ActivatedAt *time.Time `json:"activatedAt,omitempty"`
+
+	// Status is the status the response was consumed with in
+	// StageActivated: LA_OK, or an informational status such as LA_EXPIRED
+	// or LA_SUSPENDED for a genuine license or trial that is not active.
+	Status lexactivator.Status `json:"status,omitempty"`
+
+	// LastError is the error of the last failed attempt to consume a
+	// response.
+	LastError string `json:"lastError,omitempty"`
+}
+
+// Workflow is a resumable offline activation: request generated, awaiting
+// response, activated. Every transition is persisted to the state file, so
+// a workflow opened again in a later session resumes where it left off. It
+// is safe for concurrent use.
+type Workflow struct {
+	helper *Helper
+	path   string
+
+	mu    sync.Mutex
+	state WorkflowState
+}
+
+// OpenWorkflow opens the workflow persisted at path, or starts a new one of
+// the given kind if there is none.
+func OpenWorkflow(path string, helper *Helper, kind Kind) (*Workflow, error) {
+	w := &Workflow{helper: helper, path: path}
+	data, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		now := time.Now()
+		w.state = WorkflowState{Kind: kind, Stage: StageNew, CreatedAt: now, UpdatedAt: now}
+		return w, nil
+	case err != nil:
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &w.state); err != nil {
+		return nil, fmt.Errorf("offline: reading workflow %s: %w", path, err)
+	}
+	if w.state.Kind != kind {
+		return nil, fmt.Errorf("offline: workflow %s is a %s activation, not a %s one", path, w.state.Kind, kind)
+	}
+	return w, nil
+}
+
+// State returns the current state.
+func (w *Workflow) State() WorkflowState {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	state := w.state
+	state.Request = append([]byte(nil), w.state.Request...)
+	if w.state.ActivatedAt != nil {
+		activatedAt := *w.state.ActivatedAt
+		state.ActivatedAt = &activatedAt
+	}
+	return state
+}
+
+// Stage returns the current stage.
+func (w *Workflow) Stage() Stage {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.state.Stage
+}
+
+// Request generates the request in StageNew and returns it. In the later
+// stages before activation it returns the request generated before.
+func (w *Workflow) Request() ([]byte, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	switch w.state.Stage {
+	case StageRequestGenerated, StageAwaitingResponse:
+		return append([]byte(nil), w.state.Request...), nil
+	case StageNew:
+	default:
+		return nil, fmt.Errorf("%w: no request in stage %s", ErrInvalidTransition, w.state.Stage)
+	}
+	generate := w.helper.ActivationRequest
+	if w.state.Kind == KindTrial {
+		generate = w.helper.TrialActivationRequest
+	}
+	request, err := generate()
+	if err != nil {
+		return nil, err
+	}
+	w.state.Request = request
+	if err := w.transition(StageRequestGenerated); err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), request...), nil
+}
+
+// MarkSent records that the request was handed over for the response to be
+// generated.
+func (w *Workflow) MarkSent() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	switch w.state.Stage {
+	case StageAwaitingResponse:
+		return nil
+	case StageRequestGenerated:
+		return w.transition(StageAwaitingResponse)
+	}
+	return fmt.Errorf("%w: cannot mark the request sent in stage %s", ErrInvalidTransition, w.state.Stage)
+}
+
+// Complete consumes the response and moves to StageActivated. An
+// informational status such as LA_EXPIRED is not a failure: the workflow
+// is activated and the status is kept in WorkflowState.Status. On failure
+// the workflow stays in its stage, so that another response can be tried.
+func (w *Workflow) Complete(response io.Reader) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	switch w.state.Stage {
+	case StageRequestGenerated, StageAwaitingResponse:
+	default:
+		return fmt.Errorf("%w: cannot consume a response in stage %s", ErrInvalidTransition, w.state.Stage)
+	}
+	activate := w.helper.ActivateLicense
+	if w.state.Kind == KindTrial {
+		activate = w.helper.ActivateTrial
+	}
+	err := activate(response)
+	if lexactivator.IsFailure(err) {
+		w.state.LastError = err.Error()
+		if saveErr := w.save(); saveErr != nil {
+			return saveErr
+		}
+		return err
+	}
+	now := time.Now()
+	w.state.LastError = ""
+	w.state.ActivatedAt = &now
+	w.state.Status = lexactivator.StatusOf(err)
+	return w.transition(StageActivated)
+}
+
+// Reset removes the state file and starts over in StageNew.
+func (w *Workflow) Reset() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := os.Remove(w.path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	now := time.Now()
+	w.state = WorkflowState{Kind: w.state.Kind, Stage: StageNew, CreatedAt: now, UpdatedAt: now}
+	return nil
+}
+
+// transition must be called with w.mu held.
+func (w *Workflow) transition(stage Stage) error {
+	w.state.Stage = stage
+	return w.save()
+}
+
+// save writes the state file atomically. It must be called with w.mu held.
+func (w *Workflow) save() error {
+	w.state.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(w.state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := w.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, w.path)
+}
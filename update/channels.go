@@ -0,0 +1,167 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package update
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+)
+
+// ErrNoChannel is returned by Browser.Preview() when the client has no
+// release channel to restore after the preview.
+var ErrNoChannel = errors.New("update: no release channel to restore")
+
+// Catalog accumulates the releases seen per channel, since the library
+// only ever reports the latest release of the current channel. It is safe
+// for concurrent use.
+type Catalog struct {
+	mu       sync.Mutex
+	channels map[string]map[string]lexactivator.Release
+}
+
+// NewCatalog returns an empty catalog.
+func NewCatalog() *Catalog {
+	return &Catalog{channels: map[string]map[string]lexactivator.Release{}}
+}
+
+// Add records release under its channel. A release with the version of a
+// recorded one replaces it.
+func (c *Catalog) Add(release *lexactivator.Release) {
+	if release == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	releases, ok := c.channels[release.Channel]
+	if !ok {
+		releases = map[string]lexactivator.Release{}
+		c.channels[release.Channel] = releases
+	}
+	releases[release.Version] = *release
+}
+
+// Channels returns the channels with recorded releases, sorted by name.
+func (c *Catalog) Channels() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	channels := make([]string, 0, len(c.channels))
+	for channel := range c.channels {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	return channels
+}
+
+// Releases returns the releases recorded for channel, newest version
+// first. Releases with invalid versions come last.
+func (c *Catalog) Releases(channel string) []lexactivator.Release {
+	c.mu.Lock()
+	releases := make([]lexactivator.Release, 0, len(c.channels[channel]))
+	for _, release := range c.channels[channel] {
+		releases = append(releases, release)
+	}
+	c.mu.Unlock()
+	sort.SliceStable(releases, func(i, j int) bool {
+		v, errV := releases[i].ParsedVersion()
+		w, errW := releases[j].ParsedVersion()
+		if errV != nil || errW != nil {
+			return errW != nil && errV == nil
+		}
+		return v.Compare(w) > 0
+	})
+	return releases
+}
+
+// Latest returns the newest release recorded for channel, or nil.
+func (c *Catalog) Latest(channel string) *lexactivator.Release {
+	releases := c.Releases(channel)
+	if len(releases) == 0 {
+		return nil
+	}
+	return &releases[0]
+}
+
+// Browser previews the releases of other channels before switching to
+// them with SetReleaseChannel(). Every release it sees is added to its
+// Catalog.
+//
+// Previewing temporarily sets the release channel of the client, so other
+// release update checks must not run concurrently.
+type Browser struct {
+	Client  *lexactivator.Client
+	Catalog *Catalog
+
+	// Flags are passed to CheckReleaseUpdate(). Defaults to
+	// LA_RELEASES_ALL.
+	Flags uint
+
+	mu sync.Mutex
+}
+
+// NewBrowser returns a Browser for client with an empty catalog.
+func NewBrowser(client *lexactivator.Client) *Browser {
+	return &Browser{Client: client, Catalog: NewCatalog()}
+}
+
+// Channel returns the current release channel of the client.
+func (b *Browser) Channel() string {
+	return b.Client.Config().ReleaseChannel
+}
+
+// Check checks for a release on the current channel.
+func (b *Browser) Check(ctx context.Context) (lexactivator.ReleaseCheckResult, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.check(ctx)
+}
+
+// Preview checks what switching to channel would offer, then restores the
+// current channel. It returns ErrNoChannel if the client has no release
+// channel, see Config.ReleaseChannel, since the library cannot go back to
+// having none once a channel is set.
+func (b *Browser) Preview(ctx context.Context, channel string) (lexactivator.ReleaseCheckResult, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	current := b.Channel()
+	if channel == current {
+		return b.check(ctx)
+	}
+	if current == "" {
+		return lexactivator.ReleaseCheckResult{Err: ErrNoChannel}, ErrNoChannel
+	}
+	if err := b.Client.SetReleaseChannel(channel); err != nil {
+		return lexactivator.ReleaseCheckResult{Status: lexactivator.StatusOf(err), Err: err}, err
+	}
+	result, err := b.check(ctx)
+	if restoreErr := b.Client.SetReleaseChannel(current); restoreErr != nil && err == nil {
+		err = restoreErr
+	}
+	return result, err
+}
+
+// Switch commits to channel for the following release update checks.
+func (b *Browser) Switch(channel string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.Client.SetReleaseChannel(channel)
+}
+
+func (b *Browser) check(ctx context.Context) (lexactivator.ReleaseCheckResult, error) {
+	flags := b.Flags
+	if flags == 0 {
+		flags = lexactivator.LA_RELEASES_ALL
+	}
+	result, err := b.Client.CheckRelease(ctx, flags)
+	if result.Release != nil && b.Catalog != nil {
+		release := *result.Release
+		if release.Channel == "" {
+			release.Channel = b.Channel()
+		}
+		b.Catalog.Add(&release)
+	}
+	return result, err
+}
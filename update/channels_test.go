@@ -0,0 +1,126 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package update
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+	"github.com/Exostellar/lexactivator-go/fake"
+)
+
+// channelBackend offers the release of the release channel set when the
+// check starts, without a channel in the release like the library.
+type channelBackend struct {
+	*fake.Backend
+	releases map[string]string
+}
+
+func (b channelBackend) CheckReleaseUpdate(callback func(int, *lexactivator.Release, interface{}), releaseFlags uint, userData interface{}) int {
+	b.Update(func(s *fake.State) {
+		s.Release, s.ReleaseStatus = nil, lexactivator.LA_RELEASE_UPDATE_NOT_AVAILABLE
+		if version, ok := b.releases[s.ReleaseChannel]; ok {
+			s.Release = &lexactivator.Release{Version: version}
+			s.ReleaseStatus = lexactivator.LA_RELEASE_UPDATE_AVAILABLE
+		}
+	})
+	return b.Backend.CheckReleaseUpdate(callback, releaseFlags, userData)
+}
+
+func newBrowser(t *testing.T, channel string) *Browser {
+	t.Helper()
+	client, err := lexactivator.New(lexactivator.Config{
+		Backend:         channelBackend{fake.New(), map[string]string{"stable": "1.1.0", "beta": "2.0.0-beta"}},
+		ProductData:     "data",
+		ProductId:       "id",
+		ReleaseVersion:  "1.0.0",
+		ReleasePlatform: "linux",
+		ReleaseChannel:  channel,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewBrowser(client)
+}
+
+func TestBrowserPreview(t *testing.T) {
+	browser := newBrowser(t, "stable")
+	ctx := context.Background()
+	result, err := browser.Preview(ctx, "beta")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Release == nil || result.Release.Version != "2.0.0-beta" {
+		t.Fatalf("previewed %+v", result.Release)
+	}
+	if channel := browser.Channel(); channel != "stable" {
+		t.Fatalf("channel %q after the preview, want stable", channel)
+	}
+	if _, err := browser.Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if channels := browser.Catalog.Channels(); !reflect.DeepEqual(channels, []string{"beta", "stable"}) {
+		t.Fatalf("catalog channels %q", channels)
+	}
+	if latest := browser.Catalog.Latest("beta"); latest == nil || latest.Version != "2.0.0-beta" {
+		t.Fatalf("latest beta release %+v", latest)
+	}
+
+	if err := browser.Switch("beta"); err != nil {
+		t.Fatal(err)
+	}
+	if channel := browser.Channel(); channel != "beta" {
+		t.Fatalf("channel %q after the switch, want beta", channel)
+	}
+}
+
+func TestBrowserPreviewWithoutChannel(t *testing.T) {
+	browser := newBrowser(t, "")
+	if _, err := browser.Preview(context.Background(), "beta"); err != ErrNoChannel {
+		t.Fatalf("error %v, want ErrNoChannel", err)
+	}
+	if channel := browser.Client.Backend().(channelBackend).State().ReleaseChannel; channel != "" {
+		t.Fatalf("release channel %q set by the failed preview", channel)
+	}
+}
+
+func TestBrowserConcurrentSwitch(t *testing.T) {
+	browser := newBrowser(t, "stable")
+	var wg sync.WaitGroup
+	for _, channel := range []string{"stable", "beta", "stable", "beta"} {
+		wg.Add(2)
+		go func(channel string) {
+			defer wg.Done()
+			browser.Switch(channel)
+		}(channel)
+		go func() {
+			defer wg.Done()
+			browser.Channel()
+		}()
+	}
+	wg.Wait()
+	if config, state := browser.Channel(), browser.Client.Backend().(channelBackend).State().ReleaseChannel; config != state {
+		t.Fatalf("client channel %q, library channel %q", config, state)
+	}
+}
+
+func TestCatalogReleases(t *testing.T) {
+	catalog := NewCatalog()
+	for _, version := range []string{"1.2.0", "invalid", "1.10.0", "1.2.0"} {
+		catalog.Add(&lexactivator.Release{Channel: "stable", Version: version})
+	}
+	catalog.Add(nil)
+	var versions []string
+	for _, release := range catalog.Releases("stable") {
+		versions = append(versions, release.Version)
+	}
+	if want := []string{"1.10.0", "1.2.0", "invalid"}; !reflect.DeepEqual(versions, want) {
+		t.Fatalf("releases %q, want %q", versions, want)
+	}
+	if latest := catalog.Latest("beta"); latest != nil {
+		t.Fatalf("latest release of an empty channel %+v", latest)
+	}
+}
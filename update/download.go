@@ -0,0 +1,283 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+// Package update downloads and installs the releases reported by
+// CheckReleaseUpdate().
+//
+// Downloader streams the files of a release to disk, resuming interrupted
+// downloads, and verifies their size and checksum before handing them out:
+//
+//	downloader := &update.Downloader{Dir: dir}
+//	paths, err := downloader.Download(ctx, release,
+//		update.Platform("linux"), update.Extension("tar.gz"))
+package update
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+)
+
+var (
+	// ErrSizeMismatch is returned when a downloaded file does not have the
+	// size of the release file.
+	ErrSizeMismatch = errors.New("update: size mismatch")
+
+	// ErrChecksumMismatch is returned when a downloaded file does not have
+	// the checksum of the release file.
+	ErrChecksumMismatch = errors.New("update: checksum mismatch")
+
+	// ErrNoChecksum is returned for release files without a checksum,
+	// unless Downloader.AllowMissingChecksum is set.
+	ErrNoChecksum = errors.New("update: release file has no checksum")
+
+	// ErrNoFiles is returned by Download() when no file of the release is
+	// selected by the filters.
+	ErrNoFiles = errors.New("update: no release file selected")
+)
+
+// Progress reports the progress of a file download.
+type Progress struct {
+	File lexactivator.ReleaseFile
+
+	// Downloaded is the number of bytes on disk, including the ones of a
+	// resumed download.
+	Downloaded int64
+
+	// Total is the size of the file, or -1 if unknown.
+	Total int64
+}
+
+// Downloader downloads release files. Its zero value downloads to the
+// current directory using http.DefaultClient.
+type Downloader struct {
+	// Dir is the directory the files are downloaded to.
+	Dir string
+
+	// Client is the HTTP client used for downloads. Defaults to
+	// http.DefaultClient.
+	Client *http.Client
+
+	// Authorize is called with every request, e.g. to add the credentials
+	// secured release files require.
+	Authorize func(req *http.Request, file lexactivator.ReleaseFile) error
+
+	// Progress is called as file downloads progress.
+	Progress func(Progress)
+
+	// AllowMissingChecksum accepts release files without a checksum,
+	// which are otherwise refused with ErrNoChecksum.
+	AllowMissingChecksum bool
+}
+
+// Download downloads the files of the release selected by all filters and
+// returns their paths. Downloads are verified, see DownloadFile().
+func (d *Downloader) Download(ctx context.Context, release *lexactivator.Release, filters ...Filter) ([]string, error) {
+	files := SelectFiles(release, filters...)
+	if len(files) == 0 {
+		return nil, ErrNoFiles
+	}
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		path, err := d.DownloadFile(ctx, file)
+		if err != nil {
+			return paths, err
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// DownloadFile downloads a release file into Dir and returns its path.
+//
+// The file is downloaded to a ".part" file, which is resumed when it
+// already exists, and only renamed to the name of the release file once
+// its size and checksum are verified. A file failing verification is
+// removed and an error matching ErrSizeMismatch or ErrChecksumMismatch is
+// returned.
+func (d *Downloader) DownloadFile(ctx context.Context, file lexactivator.ReleaseFile) (string, error) {
+	name := filepath.Base(file.Name)
+	if file.Name == "" || name == "." || name == ".." || name != file.Name {
+		return "", fmt.Errorf("update: invalid release file name %q", file.Name)
+	}
+	if file.Checksum == "" && !d.AllowMissingChecksum {
+		return "", fmt.Errorf("%w: %s", ErrNoChecksum, file.Name)
+	}
+	path := filepath.Join(d.Dir, name)
+	partPath := path + ".part"
+	if err := d.fetch(ctx, file, partPath); err != nil {
+		return "", err
+	}
+	if err := verify(partPath, file); err != nil {
+		os.Remove(partPath)
+		return "", err
+	}
+	if err := os.Rename(partPath, path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// fetch downloads file to path, resuming from the bytes already in it.
+func (d *Downloader) fetch(ctx context.Context, file lexactivator.ReleaseFile, path string) error {
+	out, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	offset, err := out.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if file.Size > 0 && offset >= int64(file.Size) {
+		// A previous download completed but was not verified.
+		return nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, file.Url, nil)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+	if d.Authorize != nil {
+		if err := d.Authorize(req, file); err != nil {
+			return err
+		}
+	}
+	client := d.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+	case resp.StatusCode == http.StatusOK:
+		// The server ignored the range, start over.
+		if err := out.Truncate(0); err != nil {
+			return err
+		}
+		if offset, err = out.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		// The part file is complete or corrupt; verification tells.
+		return nil
+	default:
+		return fmt.Errorf("update: downloading %s: %s", file.Name, resp.Status)
+	}
+
+	total := int64(-1)
+	if file.Size > 0 {
+		total = int64(file.Size)
+	} else if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+	writer := &progressWriter{
+		w:        out,
+		progress: Progress{File: file, Downloaded: offset, Total: total},
+		report:   d.Progress,
+	}
+	if _, err := io.Copy(writer, resp.Body); err != nil {
+		return fmt.Errorf("update: downloading %s: %w", file.Name, err)
+	}
+	return out.Sync()
+}
+
+type progressWriter struct {
+	w        io.Writer
+	progress Progress
+	report   func(Progress)
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	n, err := w.w.Write(p)
+	w.progress.Downloaded += int64(n)
+	if w.report != nil && n > 0 {
+		w.report(w.progress)
+	}
+	return n, err
+}
+
+// verify checks the size and checksum of the file at path.
+func verify(path string, file lexactivator.ReleaseFile) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if file.Size > 0 && info.Size() != int64(file.Size) {
+		return fmt.Errorf("%w: %s is %d bytes, expected %d", ErrSizeMismatch, file.Name, info.Size(), file.Size)
+	}
+	if file.Checksum == "" {
+		return nil
+	}
+	h, expected, err := checksumHash(file.Checksum)
+	if err != nil {
+		return fmt.Errorf("update: %s: %w", file.Name, err)
+	}
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != expected {
+		return fmt.Errorf("%w: %s has checksum %s, expected %s", ErrChecksumMismatch, file.Name, actual, expected)
+	}
+	return nil
+}
+
+// checksumHash returns the hash for a checksum, either prefixed with the
+// algorithm, e.g. "sha256:<hex>", or plain hex whose length tells the
+// algorithm.
+func checksumHash(checksum string) (hash.Hash, string, error) {
+	algorithm, digest := "", strings.ToLower(checksum)
+	if i := strings.Index(digest, ":"); i >= 0 {
+		algorithm, digest = digest[:i], digest[i+1:]
+	}
+	if algorithm == "" {
+		switch len(digest) {
+		case 2 * md5.Size:
+			algorithm = "md5"
+		case 2 * sha1.Size:
+			algorithm = "sha1"
+		case 2 * sha256.Size:
+			algorithm = "sha256"
+		case 2 * sha512.Size:
+			algorithm = "sha512"
+		}
+	}
+	switch algorithm {
+	case "md5":
+		return md5.New(), digest, nil
+	case "sha1":
+		return sha1.New(), digest, nil
+	case "sha256":
+		return sha256.New(), digest, nil
+	case "sha512":
+		return sha512.New(), digest, nil
+	}
+	return nil, "", fmt.Errorf("unsupported checksum %q", checksum)
+}
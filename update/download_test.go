@@ -0,0 +1,150 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package update
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+)
+
+// fileServer serves release files by name and records the Range headers of
+// the requests.
+type fileServer struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	files  map[string][]byte
+	ranges []string
+}
+
+func newFileServer(t *testing.T, files map[string][]byte) *fileServer {
+	s := &fileServer{files: files}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.ranges = append(s.ranges, r.Header.Get("Range"))
+		content, ok := s.files[strings.TrimPrefix(r.URL.Path, "/")]
+		s.mu.Unlock()
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		http.ServeContent(w, r, r.URL.Path, time.Time{}, bytes.NewReader(content))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// releaseFile returns the release file for name as served by s.
+func (s *fileServer) releaseFile(name string) lexactivator.ReleaseFile {
+	sum := sha256.Sum256(s.files[name])
+	return lexactivator.ReleaseFile{
+		Name:     name,
+		Url:      s.URL + "/" + name,
+		Size:     len(s.files[name]),
+		Checksum: "sha256:" + hex.EncodeToString(sum[:]),
+	}
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "update-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+func TestDownload(t *testing.T) {
+	server := newFileServer(t, map[string][]byte{
+		"app-linux.tar.gz": []byte("linux archive"),
+		"app-windows.zip":  []byte("windows archive"),
+	})
+	release := &lexactivator.Release{
+		Platforms: []string{"linux", "windows"},
+		Files: []lexactivator.ReleaseFile{
+			server.releaseFile("app-windows.zip"),
+			server.releaseFile("app-linux.tar.gz"),
+		},
+	}
+	var progress []Progress
+	downloader := &Downloader{Dir: tempDir(t), Progress: func(p Progress) { progress = append(progress, p) }}
+	paths, err := downloader.Download(context.Background(), release, Platform("linux"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 || filepath.Base(paths[0]) != "app-linux.tar.gz" {
+		t.Fatalf("downloaded %v", paths)
+	}
+	if content, _ := ioutil.ReadFile(paths[0]); string(content) != "linux archive" {
+		t.Fatalf("downloaded %q", content)
+	}
+	if last := progress[len(progress)-1]; last.Downloaded != 13 || last.Total != 13 {
+		t.Fatalf("last progress %+v", last)
+	}
+	if _, err := downloader.Download(context.Background(), release, Platform("darwin")); err != ErrNoFiles {
+		t.Fatalf("error %v, want ErrNoFiles", err)
+	}
+}
+
+func TestDownloadResume(t *testing.T) {
+	content := []byte("0123456789abcdefghij")
+	server := newFileServer(t, map[string][]byte{"app": content})
+	downloader := &Downloader{Dir: tempDir(t)}
+	path := filepath.Join(downloader.Dir, "app")
+	if err := ioutil.WriteFile(path+".part", content[:8], 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := downloader.DownloadFile(context.Background(), server.releaseFile("app")); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := ioutil.ReadFile(path); !bytes.Equal(got, content) {
+		t.Fatalf("downloaded %q", got)
+	}
+	if len(server.ranges) != 1 || server.ranges[0] != "bytes=8-" {
+		t.Fatalf("requested ranges %q", server.ranges)
+	}
+}
+
+func TestDownloadVerify(t *testing.T) {
+	server := newFileServer(t, map[string][]byte{"app": []byte("tampered")})
+	downloader := &Downloader{Dir: tempDir(t)}
+	file := server.releaseFile("app")
+
+	badChecksum := file
+	badChecksum.Checksum = strings.Repeat("0", 64)
+	if _, err := downloader.DownloadFile(context.Background(), badChecksum); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("error %v, want ErrChecksumMismatch", err)
+	}
+	badSize := file
+	badSize.Size++
+	if _, err := downloader.DownloadFile(context.Background(), badSize); !errors.Is(err, ErrSizeMismatch) {
+		t.Fatalf("error %v, want ErrSizeMismatch", err)
+	}
+	if entries, _ := ioutil.ReadDir(downloader.Dir); len(entries) != 0 {
+		t.Fatalf("%d files left after failed verifications", len(entries))
+	}
+
+	noChecksum := file
+	noChecksum.Checksum = ""
+	if _, err := downloader.DownloadFile(context.Background(), noChecksum); !errors.Is(err, ErrNoChecksum) {
+		t.Fatalf("error %v, want ErrNoChecksum", err)
+	}
+	downloader.AllowMissingChecksum = true
+	if _, err := downloader.DownloadFile(context.Background(), noChecksum); err != nil {
+		t.Fatal(err)
+	}
+}
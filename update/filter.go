@@ -0,0 +1,72 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package update
+
+import (
+	"strings"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+)
+
+// Filter selects the files of a release to download.
+type Filter func(release *lexactivator.Release, file lexactivator.ReleaseFile) bool
+
+// Extension selects the files with one of the given extensions, with or
+// without the leading dot, e.g. "tar.gz" or ".zip". Extensions are
+// compared case-insensitively.
+func Extension(extensions ...string) Filter {
+	return func(release *lexactivator.Release, file lexactivator.ReleaseFile) bool {
+		for _, extension := range extensions {
+			extension = strings.ToLower(strings.TrimPrefix(extension, "."))
+			if strings.ToLower(strings.TrimPrefix(file.Extension, ".")) == extension ||
+				strings.HasSuffix(strings.ToLower(file.Name), "."+extension) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Platform selects the files of releases published for the given platform,
+// e.g. "linux". When a release is published for several platforms, only
+// the files whose name contains the platform are selected.
+func Platform(platform string) Filter {
+	platform = strings.ToLower(platform)
+	return func(release *lexactivator.Release, file lexactivator.ReleaseFile) bool {
+		found := false
+		for _, p := range release.Platforms {
+			if strings.ToLower(p) == platform {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+		return len(release.Platforms) == 1 || strings.Contains(strings.ToLower(file.Name), platform)
+	}
+}
+
+// Name selects the file with the given name.
+func Name(name string) Filter {
+	return func(release *lexactivator.Release, file lexactivator.ReleaseFile) bool {
+		return file.Name == name
+	}
+}
+
+// SelectFiles returns the files of the release selected by all filters.
+func SelectFiles(release *lexactivator.Release, filters ...Filter) []lexactivator.ReleaseFile {
+	var files []lexactivator.ReleaseFile
+	for _, file := range release.Files {
+		selected := true
+		for _, filter := range filters {
+			if !filter(release, file) {
+				selected = false
+				break
+			}
+		}
+		if selected {
+			files = append(files, file)
+		}
+	}
+	return files
+}
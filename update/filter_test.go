@@ -0,0 +1,51 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package update
+
+import (
+	"reflect"
+	"testing"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+)
+
+func TestSelectFiles(t *testing.T) {
+	release := &lexactivator.Release{
+		Platforms: []string{"linux", "Windows"},
+		Files: []lexactivator.ReleaseFile{
+			{Name: "app-linux.tar.gz"},
+			{Name: "app-linux.zip", Extension: "zip"},
+			{Name: "app-windows.ZIP"},
+			{Name: "notes.txt"},
+		},
+	}
+	single := &lexactivator.Release{
+		Platforms: []string{"linux"},
+		Files:     []lexactivator.ReleaseFile{{Name: "app.tar.gz"}},
+	}
+	tests := []struct {
+		name    string
+		release *lexactivator.Release
+		filters []Filter
+		want    []string
+	}{
+		{"no filter", release, nil, []string{"app-linux.tar.gz", "app-linux.zip", "app-windows.ZIP", "notes.txt"}},
+		{"platform", release, []Filter{Platform("linux")}, []string{"app-linux.tar.gz", "app-linux.zip"}},
+		{"platform case", release, []Filter{Platform("windows")}, []string{"app-windows.ZIP"}},
+		{"platform not published", release, []Filter{Platform("darwin")}, nil},
+		{"single platform", single, []Filter{Platform("linux")}, []string{"app.tar.gz"}},
+		{"extension", release, []Filter{Extension(".zip")}, []string{"app-linux.zip", "app-windows.ZIP"}},
+		{"extensions", release, []Filter{Extension("tar.gz", "txt")}, []string{"app-linux.tar.gz", "notes.txt"}},
+		{"platform and extension", release, []Filter{Platform("linux"), Extension("zip")}, []string{"app-linux.zip"}},
+		{"name", release, []Filter{Name("notes.txt")}, []string{"notes.txt"}},
+	}
+	for _, test := range tests {
+		var names []string
+		for _, file := range SelectFiles(test.release, test.filters...) {
+			names = append(names, file.Name)
+		}
+		if !reflect.DeepEqual(names, test.want) {
+			t.Errorf("%s: selected %q, want %q", test.name, names, test.want)
+		}
+	}
+}
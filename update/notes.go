@@ -0,0 +1,64 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package update
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	markdownHeading    = regexp.MustCompile(`^\s{0,3}#{1,6}\s+`)
+	markdownQuote      = regexp.MustCompile(`^\s*>\s?`)
+	markdownListItem   = regexp.MustCompile(`^(\s*)[-*+]\s+`)
+	markdownRule       = regexp.MustCompile(`^\s{0,3}([-*_]\s*){3,}$`)
+	markdownImage      = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLink       = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)[^)]*\)`)
+	markdownCode       = regexp.MustCompile("`([^`]+)`")
+	markdownStrong     = regexp.MustCompile(`\*\*(\S(?:.*?\S)?)\*\*`)
+	markdownEmphasis   = regexp.MustCompile(`\*(\S(?:.*?\S)?)\*`)
+	markdownStrike     = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	markdownUnderline  = regexp.MustCompile(`(^|\W)__(\S(?:.*?\S)?)__(\W|$)`)
+	markdownUnderscore = regexp.MustCompile(`(^|\W)_(\S(?:.*?\S)?)_(\W|$)`)
+	markdownHTMLTag    = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+	markdownBlankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// PlainTextNotes renders the markdown of Release.Notes as plain text for
+// terminal display. Headings, emphasis, code and HTML markup are removed,
+// list items are shown with a bullet and links as "text (url)".
+func PlainTextNotes(notes string) string {
+	lines := strings.Split(strings.Replace(notes, "\r\n", "\n", -1), "\n")
+	inFence := false
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inFence = !inFence
+			lines[i] = ""
+			continue
+		}
+		if inFence {
+			lines[i] = "    " + line
+			continue
+		}
+		if markdownRule.MatchString(line) {
+			lines[i] = ""
+			continue
+		}
+		line = markdownHeading.ReplaceAllString(line, "")
+		line = markdownQuote.ReplaceAllString(line, "")
+		line = markdownListItem.ReplaceAllString(line, "$1• ")
+		line = markdownImage.ReplaceAllString(line, "$1")
+		line = markdownLink.ReplaceAllString(line, "$1 ($2)")
+		line = markdownCode.ReplaceAllString(line, "$1")
+		line = markdownStrong.ReplaceAllString(line, "$1")
+		line = markdownEmphasis.ReplaceAllString(line, "$1")
+		line = markdownStrike.ReplaceAllString(line, "$1")
+		line = markdownUnderline.ReplaceAllString(line, "$1$2$3")
+		line = markdownUnderscore.ReplaceAllString(line, "$1$2$3")
+		line = markdownHTMLTag.ReplaceAllString(line, "")
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	text := strings.Join(lines, "\n")
+	text = markdownBlankLines.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text)
+}
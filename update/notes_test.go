@@ -0,0 +1,31 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package update
+
+import "testing"
+
+func TestPlainTextNotes(t *testing.T) {
+	tests := []struct {
+		name  string
+		notes string
+		want  string
+	}{
+		{"heading", "## What's new\r\nFaster exports", "What's new\nFaster exports"},
+		{"list", "- one\n  * two\n+ three", "• one\n  • two\n• three"},
+		{"quote", "> Breaking change", "Breaking change"},
+		{"rule", "before\n---\nafter", "before\n\nafter"},
+		{"link", "See [the docs](https://example.com \"Docs\").", "See the docs (https://example.com)."},
+		{"image", "![screenshot](shot.png)", "screenshot"},
+		{"emphasis", "**bold** *italic* ~~gone~~ __under__ _score_", "bold italic gone under score"},
+		{"identifier", "set snake_case_name", "set snake_case_name"},
+		{"code", "Run `app --update`", "Run app --update"},
+		{"fence", "```\n**raw**\n```", "**raw**"},
+		{"html", "<b>Note</b><br/>", "Note"},
+		{"blank lines", "one\n\n\n\ntwo  ", "one\n\ntwo"},
+	}
+	for _, test := range tests {
+		if got := PlainTextNotes(test.notes); got != test.want {
+			t.Errorf("%s: %q, want %q", test.name, got, test.want)
+		}
+	}
+}
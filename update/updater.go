@@ -0,0 +1,396 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+)
+
+// ErrNoUpdate is returned by Updater.Update() when no update is available.
+var ErrNoUpdate = errors.New("update: no update available")
+
+// Updater keeps the running executable up to date with the releases
+// reported by CheckReleaseUpdate().
+//
+// An update downloads and verifies the release file selected by Filters,
+// replaces the executable by renaming the new one over it, which is atomic
+// on Linux and macOS, and keeps the replaced executable as a rollback copy
+// next to it. The version of the installed release is recorded in a state
+// file, and passed to SetReleaseVersion() by Start() on the next start of
+// the program:
+//
+//	updater := &update.Updater{
+//		Client:  client,
+//		Filters: []update.Filter{update.Platform("linux")},
+//	}
+//	if err := updater.Start(); err != nil {
+//		...
+//	}
+//	go updater.Run(ctx)
+type Updater struct {
+	// Client is used to check for updates and set the release version.
+	// When nil, the package level functions are used.
+	Client *lexactivator.Client
+
+	// Check checks for an update. Defaults to CheckRelease() with
+	// LA_RELEASES_ALLOWED on Client or the package.
+	Check func(ctx context.Context) (lexactivator.ReleaseCheckResult, error)
+
+	// Downloader downloads the release files. Without a Dir, the files are
+	// downloaded to a temporary directory removed once the update is
+	// applied. Defaults to such a Downloader.
+	Downloader *Downloader
+
+	// Filters select the release file to install. The first selected file
+	// is installed.
+	Filters []Filter
+
+	// Extract returns the path of the new executable within a downloaded
+	// release file, e.g. after unpacking an archive next to it, which is
+	// removed with the temporary download directory. By default the
+	// release file is the new executable.
+	Extract func(downloadedPath string) (executablePath string, err error)
+
+	// Verify checks the installed executable, e.g. by running it with a
+	// --version flag. The update is rolled back when it fails.
+	Verify func(executable string) error
+
+	// Executable is the path of the executable to update. Defaults to
+	// os.Executable().
+	Executable string
+
+	// StateFile records the installed release version. Defaults to the
+	// executable path with a ".update.json" suffix.
+	StateFile string
+
+	// Interval is the interval between the checks of Run(). Defaults to
+	// 24 hours.
+	Interval time.Duration
+
+	// Hooks. OnUpdateAvailable may return false to skip the release.
+	OnUpdateAvailable func(release *lexactivator.Release) bool
+	OnDownloaded      func(release *lexactivator.Release, path string)
+	OnApplied         func(release *lexactivator.Release)
+	OnRolledBack      func(release *lexactivator.Release, err error)
+
+	// OnError is called with the errors of the checks done by Run().
+	OnError func(err error)
+}
+
+// State is the content of the state file.
+type State struct {
+	// Version is the version of the installed release.
+	Version string `json:"version"`
+
+	// PreviousVersion is the version of the rollback copy, if any.
+	PreviousVersion string `json:"previousVersion,omitempty"`
+
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Start passes the version recorded by the last update, if any, to
+// SetReleaseVersion(). It must be called on every start of the program,
+// after the product is configured.
+func (u *Updater) Start() error {
+	state, err := u.State()
+	if err != nil || state.Version == "" {
+		return err
+	}
+	if u.Client != nil {
+		return lexactivator.StatusToError(u.Client.Backend().SetReleaseVersion(state.Version))
+	}
+	return lexactivator.StatusToError(lexactivator.SetReleaseVersion(state.Version))
+}
+
+// State returns the recorded state. It is empty before the first update.
+func (u *Updater) State() (State, error) {
+	var state State
+	path, err := u.stateFile()
+	if err != nil {
+		return state, err
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("update: reading %s: %w", path, err)
+	}
+	return state, nil
+}
+
+// Run checks for updates and applies them every Interval until ctx is
+// done.
+func (u *Updater) Run(ctx context.Context) {
+	interval := u.Interval
+	if interval <= 0 {
+		interval = 24 * time.Hour
+	}
+	for {
+		if _, err := u.Update(ctx); err != nil && !errors.Is(err, ErrNoUpdate) && u.OnError != nil {
+			u.OnError(err)
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+	}
+}
+
+// Update checks for an update and applies it. It returns the applied
+// release, or ErrNoUpdate when no update is available or allowed, or it is
+// skipped by OnUpdateAvailable. A check failing with any other status
+// returns the error of the status.
+func (u *Updater) Update(ctx context.Context) (*lexactivator.Release, error) {
+	result, err := u.check(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch int(result.Status) {
+	case lexactivator.LA_RELEASE_UPDATE_AVAILABLE:
+	case lexactivator.LA_RELEASE_UPDATE_NOT_AVAILABLE, lexactivator.LA_RELEASE_UPDATE_AVAILABLE_NOT_ALLOWED:
+		return nil, ErrNoUpdate
+	default:
+		return nil, lexactivator.StatusToError(int(result.Status))
+	}
+	if result.Release == nil {
+		return nil, errors.New("update: no release reported with the available update")
+	}
+	release := result.Release
+	if u.OnUpdateAvailable != nil && !u.OnUpdateAvailable(release) {
+		return nil, ErrNoUpdate
+	}
+	executable, err := u.executable()
+	if err != nil {
+		return nil, err
+	}
+	downloader := &Downloader{}
+	if u.Downloader != nil {
+		*downloader = *u.Downloader
+	}
+	if downloader.Dir == "" {
+		if downloader.Dir, err = ioutil.TempDir("", "update"); err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(downloader.Dir)
+	}
+	// A release file downloaded over the executable would replace it
+	// before Apply() keeps the rollback copy.
+	for _, file := range SelectFiles(release, u.Filters...) {
+		if path := filepath.Join(downloader.Dir, filepath.Base(file.Name)); sameFile(path, executable) {
+			return nil, fmt.Errorf("update: release file %s would be downloaded over the executable", file.Name)
+		}
+	}
+	paths, err := downloader.Download(ctx, release, u.Filters...)
+	if err != nil {
+		return nil, err
+	}
+	if u.OnDownloaded != nil {
+		u.OnDownloaded(release, paths[0])
+	}
+	newExecutable := paths[0]
+	if u.Extract != nil {
+		if newExecutable, err = u.Extract(paths[0]); err != nil {
+			return nil, err
+		}
+	}
+	if err := u.Apply(release, newExecutable); err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
+// Apply installs newExecutable as the executable of release, keeping the
+// current executable as the rollback copy. The update is rolled back if
+// the state file cannot be written or Verify fails.
+func (u *Updater) Apply(release *lexactivator.Release, newExecutable string) error {
+	executable, err := u.executable()
+	if err != nil {
+		return err
+	}
+	state, err := u.State()
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(executable)
+	if err != nil {
+		return err
+	}
+	// The new executable is staged next to the current one so that the
+	// final rename does not cross file systems.
+	staged := executable + ".new"
+	if err := copyFile(newExecutable, staged, info.Mode()); err != nil {
+		return err
+	}
+	backup := executable + ".old"
+	os.Remove(backup)
+	if err := os.Link(executable, backup); err != nil {
+		if err := copyFile(executable, backup, info.Mode()); err != nil {
+			os.Remove(staged)
+			return err
+		}
+	}
+	if err := os.Rename(staged, executable); err != nil {
+		os.Remove(staged)
+		return err
+	}
+	if err := u.writeState(State{Version: release.Version, PreviousVersion: state.Version, UpdatedAt: time.Now()}); err != nil {
+		// The state file still records the replaced executable, so only
+		// the executable is restored.
+		stateErr := fmt.Errorf("update: recording %s: %w", release.Version, err)
+		if rollbackErr := restoreBackup(executable); rollbackErr != nil {
+			return rollbackErr
+		}
+		if u.OnRolledBack != nil {
+			u.OnRolledBack(release, stateErr)
+		}
+		return stateErr
+	}
+	if u.Verify != nil {
+		if err := u.Verify(executable); err != nil {
+			verifyErr := fmt.Errorf("update: verifying %s: %w", release.Version, err)
+			if rollbackErr := u.rollback(release, verifyErr); rollbackErr != nil {
+				return rollbackErr
+			}
+			return verifyErr
+		}
+	}
+	if u.OnApplied != nil {
+		u.OnApplied(release)
+	}
+	return nil
+}
+
+// Rollback restores the rollback copy kept by the last update.
+func (u *Updater) Rollback() error {
+	return u.rollback(nil, nil)
+}
+
+func (u *Updater) rollback(release *lexactivator.Release, cause error) error {
+	executable, err := u.executable()
+	if err != nil {
+		return err
+	}
+	state, err := u.State()
+	if err != nil {
+		return err
+	}
+	if err := restoreBackup(executable); err != nil {
+		return err
+	}
+	if err := u.writeState(State{Version: state.PreviousVersion, UpdatedAt: time.Now()}); err != nil {
+		return err
+	}
+	if u.OnRolledBack != nil {
+		u.OnRolledBack(release, cause)
+	}
+	return nil
+}
+
+// restoreBackup renames the rollback copy over executable.
+func restoreBackup(executable string) error {
+	backup := executable + ".old"
+	if _, err := os.Stat(backup); err != nil {
+		return fmt.Errorf("update: no rollback copy: %w", err)
+	}
+	return os.Rename(backup, executable)
+}
+
+func (u *Updater) check(ctx context.Context) (lexactivator.ReleaseCheckResult, error) {
+	switch {
+	case u.Check != nil:
+		return u.Check(ctx)
+	case u.Client != nil:
+		return u.Client.CheckRelease(ctx, lexactivator.LA_RELEASES_ALLOWED)
+	}
+	return lexactivator.CheckRelease(ctx, lexactivator.LA_RELEASES_ALLOWED)
+}
+
+func (u *Updater) executable() (string, error) {
+	if u.Executable != "" {
+		return u.Executable, nil
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(executable)
+}
+
+func (u *Updater) stateFile() (string, error) {
+	if u.StateFile != "" {
+		return u.StateFile, nil
+	}
+	executable, err := u.executable()
+	if err != nil {
+		return "", err
+	}
+	return executable + ".update.json", nil
+}
+
+func (u *Updater) writeState(state State) error {
+	path, err := u.stateFile()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// sameFile reports whether path names the existing file executable.
+func sameFile(path, executable string) bool {
+	if filepath.Clean(path) == filepath.Clean(executable) {
+		return true
+	}
+	pathInfo, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	executableInfo, err := os.Stat(executable)
+	return err == nil && os.SameFile(pathInfo, executableInfo)
+}
+
+func copyFile(src, dst string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,201 @@
+// Copyright 2023 Cryptlex, LLC. All rights reserved.
+
+package update
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	lexactivator "github.com/Exostellar/lexactivator-go"
+)
+
+// newTestUpdater returns an updater of an executable named "app" in its own
+// directory, offered release 2.0.0 with files served by server.
+func newTestUpdater(t *testing.T, server *fileServer, names ...string) *Updater {
+	dir := tempDir(t)
+	executable := filepath.Join(dir, "app")
+	if err := ioutil.WriteFile(executable, []byte("1.0.0"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	release := &lexactivator.Release{Version: "2.0.0", Platforms: []string{"linux"}}
+	for _, name := range names {
+		release.Files = append(release.Files, server.releaseFile(name))
+	}
+	return &Updater{
+		Check: func(ctx context.Context) (lexactivator.ReleaseCheckResult, error) {
+			return lexactivator.ReleaseCheckResult{
+				Status:  lexactivator.Status(lexactivator.LA_RELEASE_UPDATE_AVAILABLE),
+				Release: release,
+			}, nil
+		},
+		Executable: executable,
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+// dirNames returns the sorted names of the files in dir.
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestUpdate(t *testing.T) {
+	server := newFileServer(t, map[string][]byte{
+		"app-linux":   []byte("2.0.0"),
+		"app-windows": []byte("2.0.0 for windows"),
+	})
+	updater := newTestUpdater(t, server, "app-windows", "app-linux")
+	updater.Filters = []Filter{Name("app-linux")}
+	var downloaded string
+	updater.OnDownloaded = func(release *lexactivator.Release, path string) { downloaded = path }
+
+	release, err := updater.Update(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.Version != "2.0.0" {
+		t.Fatalf("applied release %s", release.Version)
+	}
+	executable := updater.Executable
+	if content := readFile(t, executable); content != "2.0.0" {
+		t.Fatalf("executable is %q", content)
+	}
+	if content := readFile(t, executable+".old"); content != "1.0.0" {
+		t.Fatalf("rollback copy is %q", content)
+	}
+	if state, err := updater.State(); err != nil || state.Version != "2.0.0" {
+		t.Fatalf("state %+v, %v", state, err)
+	}
+	if filepath.Dir(downloaded) == filepath.Dir(executable) {
+		t.Fatalf("downloaded next to the executable: %s", downloaded)
+	}
+	if _, err := os.Stat(filepath.Dir(downloaded)); !os.IsNotExist(err) {
+		t.Fatalf("download directory left behind: %v", err)
+	}
+	want := []string{"app", "app.old", "app.update.json"}
+	if names := dirNames(t, filepath.Dir(executable)); !reflect.DeepEqual(names, want) {
+		t.Fatalf("executable directory holds %q, want %q", names, want)
+	}
+}
+
+func TestUpdateReleaseFileNamedLikeExecutable(t *testing.T) {
+	server := newFileServer(t, map[string][]byte{"app": []byte("2.0.0")})
+	updater := newTestUpdater(t, server, "app")
+	if _, err := updater.Update(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if content := readFile(t, updater.Executable+".old"); content != "1.0.0" {
+		t.Fatalf("rollback copy is %q", content)
+	}
+
+	updater = newTestUpdater(t, server, "app")
+	updater.Downloader = &Downloader{Dir: filepath.Dir(updater.Executable)}
+	if _, err := updater.Update(context.Background()); err == nil {
+		t.Fatal("downloaded over the executable")
+	}
+	if content := readFile(t, updater.Executable); content != "1.0.0" {
+		t.Fatalf("executable is %q", content)
+	}
+}
+
+func TestUpdateVerifyFailure(t *testing.T) {
+	server := newFileServer(t, map[string][]byte{"app-linux": []byte("2.0.0")})
+	updater := newTestUpdater(t, server, "app-linux")
+	if err := updater.writeState(State{Version: "1.0.0"}); err != nil {
+		t.Fatal(err)
+	}
+	verifyErr := errors.New("does not start")
+	updater.Verify = func(executable string) error {
+		if content := readFile(t, executable); content != "2.0.0" {
+			t.Errorf("verifying %q", content)
+		}
+		return verifyErr
+	}
+	var rolledBack error
+	updater.OnRolledBack = func(release *lexactivator.Release, err error) { rolledBack = err }
+
+	if _, err := updater.Update(context.Background()); !errors.Is(err, verifyErr) {
+		t.Fatalf("error %v, want the Verify error", err)
+	}
+	if !errors.Is(rolledBack, verifyErr) {
+		t.Fatalf("OnRolledBack called with %v", rolledBack)
+	}
+	if content := readFile(t, updater.Executable); content != "1.0.0" {
+		t.Fatalf("executable is %q after rollback", content)
+	}
+	if state, err := updater.State(); err != nil || state.Version != "1.0.0" {
+		t.Fatalf("state %+v, %v", state, err)
+	}
+}
+
+func TestApplyStateFailure(t *testing.T) {
+	server := newFileServer(t, map[string][]byte{"app-linux": []byte("2.0.0")})
+	updater := newTestUpdater(t, server, "app-linux")
+	// The state file cannot be written into a directory that does not
+	// exist.
+	updater.StateFile = filepath.Join(filepath.Dir(updater.Executable), "missing", "state.json")
+	var rolledBack error
+	updater.OnRolledBack = func(release *lexactivator.Release, err error) { rolledBack = err }
+
+	if _, err := updater.Update(context.Background()); err == nil || err != rolledBack {
+		t.Fatalf("error %v, OnRolledBack called with %v", err, rolledBack)
+	}
+	if content := readFile(t, updater.Executable); content != "1.0.0" {
+		t.Fatalf("executable is %q after rollback", content)
+	}
+}
+
+func TestUpdateCheckStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		err    error
+	}{
+		{lexactivator.LA_RELEASE_UPDATE_NOT_AVAILABLE, ErrNoUpdate},
+		{lexactivator.LA_RELEASE_UPDATE_AVAILABLE_NOT_ALLOWED, ErrNoUpdate},
+		{lexactivator.LA_E_INET, lexactivator.ErrInet},
+		{lexactivator.LA_FAIL, lexactivator.ErrFail},
+	}
+	for _, test := range tests {
+		updater := &Updater{
+			Check: func(ctx context.Context) (lexactivator.ReleaseCheckResult, error) {
+				return lexactivator.ReleaseCheckResult{Status: lexactivator.Status(test.status)}, nil
+			},
+			Executable: filepath.Join(tempDir(t), "app"),
+		}
+		if _, err := updater.Update(context.Background()); !errors.Is(err, test.err) {
+			t.Errorf("%s: error %v, want %v", lexactivator.Status(test.status), err, test.err)
+		}
+
+		var reported error
+		updater.OnError = func(err error) { reported = err }
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		updater.Run(ctx)
+		if test.err == ErrNoUpdate && reported != nil || test.err != ErrNoUpdate && !errors.Is(reported, test.err) {
+			t.Errorf("%s: Run reported %v", lexactivator.Status(test.status), reported)
+		}
+	}
+}
@@ -1,4 +1,5 @@
-// +build windows
+//go:build windows && cgo && !lexactivator_stub
+// +build windows,cgo,!lexactivator_stub
 
 package lexactivator
 